@@ -1,104 +1,262 @@
-package main
-
-import (
-	"fmt"
-	"log"
-
-	_ "rag-api/docs"
-	"rag-api/internal/adapter/openai"
-	"rag-api/internal/adapter/repository/postgres"
-	"rag-api/internal/delivery/http/handler"
-	"rag-api/internal/delivery/http/middleware"
-	"rag-api/internal/usecase/auth"
-	"rag-api/internal/usecase/document"
-	"rag-api/pkg/config"
-	"rag-api/pkg/database"
-
-	"github.com/gofiber/fiber/v2"
-	fiberSwagger "github.com/swaggo/fiber-swagger"
-
-	// log
-	"github.com/gofiber/fiber/v2/middleware/logger"
-)
-
-// @title           RAG API
-// @version         1.0
-// @description     API documentation for the RAG (Retrieval-Augmented Generation) service
-// @host            localhost:8080
-// @BasePath        /
-// @securityDefinitions.apikey BearerAuth
-// @in header
-// @name Authorization
-// @description Type "Bearer" followed by a space and JWT token.
-func main() {
-	cfg := config.Load()
-
-	// connect to database
-	db, err := database.Connect(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
-	}
-	defer db.Close()
-	log.Println("connected to database")
-
-	// initialize openai client
-	embeddingClient := openai.NewEmbeddingClient(cfg.OpenAIKey, cfg.OpenAIEmbeddingModel)
-	chatClient := openai.NewChatClient(cfg.OpenAIKey, cfg.OpenAIChatModel)
-
-	// initialize repository
-	userRepo := postgres.NewUserRepository(db)
-	docRepo := postgres.NewDocumentRepository(db)
-	chunkRepo := postgres.NewChunkRepository(db)
-
-	// initialize usecase
-	authUsecase := auth.NewAuthUsecase(userRepo, cfg.JWTSecret, cfg.JWTExpiration)
-	docUsecase := document.NewDocumentUsecase(
-		docRepo,
-		chunkRepo,
-		embeddingClient,
-		chatClient,
-		cfg.ChunkSize,
-		cfg.ChunkOverlap,
-		cfg.TopKResults,
-		cfg.SimilarityThreshold,
-	)
-
-	// initialize handler
-	authHandler := handler.NewAuthHandler(authUsecase)
-	docHandler := handler.NewDocumentHandler(docUsecase)
-
-	// initialize fiber app
-	app := fiber.New()
-
-	// middleware for log request and response in terminal
-	app.Use(logger.New())
-
-	// Swagger route
-	app.Get("/swagger/*", fiberSwagger.WrapHandler)
-
-	// Public Routes
-	api := app.Group("/api")
-	api.Post("/auth/register", authHandler.Register)
-	api.Post("/auth/login", authHandler.Login)
-
-	// Protected Routes
-	protected := api.Group("", middleware.JWTAuth(cfg.JWTSecret))
-	protected.Get("/auth/me", authHandler.Me)
-
-	// document routes
-	protected.Post("/documents/upload", docHandler.Upload)
-	protected.Get("/documents", docHandler.List)
-	protected.Get("/documents/:id", docHandler.GetByID)
-	protected.Delete("/documents/:id", docHandler.Delete)
-	protected.Post("/documents/query", docHandler.Query)
-
-	//
-	//
-	//
-	// Start server
-	log.Printf("🚀 Server starting on port %d", cfg.Port)
-	log.Printf("📚 Swagger UI: http://localhost:%d/swagger/index.html", cfg.Port)
-	if err := app.Listen(fmt.Sprintf(":%d", cfg.Port)); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	_ "rag-api/docs"
+	"rag-api/internal/adapter/blob"
+	"rag-api/internal/adapter/llm"
+	"rag-api/internal/adapter/ocr"
+	"rag-api/internal/adapter/repository/postgres"
+	"rag-api/internal/adapter/rerank"
+	"rag-api/internal/delivery/http/handler"
+	"rag-api/internal/delivery/http/middleware"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/auth"
+	"rag-api/internal/usecase/chat"
+	"rag-api/internal/usecase/document"
+	"rag-api/internal/usecase/ingestion"
+	"rag-api/pkg/config"
+	"rag-api/pkg/database"
+	"rag-api/pkg/tokenizer"
+
+	"github.com/gofiber/fiber/v2"
+	fiberSwagger "github.com/swaggo/fiber-swagger"
+
+	// log
+	"github.com/gofiber/fiber/v2/middleware/logger"
+)
+
+// @title           RAG API
+// @version         1.0
+// @description     API documentation for the RAG (Retrieval-Augmented Generation) service
+// @host            localhost:8080
+// @BasePath        /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and JWT token.
+func main() {
+	cfg := config.Load()
+
+	// connect to database
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	log.Println("connected to database")
+
+	// initialize llm provider
+	provider, err := llm.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize LLM provider: %v", err)
+	}
+	log.Printf("using LLM provider %s", cfg.LLMProvider)
+
+	// fail fast when the embedding model doesn't fit the vector column
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 30*time.Second)
+	err = llm.CheckEmbeddingDimensions(checkCtx, provider.Embedding)
+	cancelCheck()
+	if err != nil {
+		log.Fatalf("failed to check the embedding model: %v", err)
+	}
+	embeddingClient := provider.Embedding
+	chatClient := provider.Chat
+
+	// initialize blob storage
+	blobStore, err := blob.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize blob storage: %v", err)
+	}
+	log.Printf("storing files in %s blob storage", cfg.BlobStorage)
+
+	// initialize ocr engine, nil when disabled
+	ocrEngine, err := ocr.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize OCR engine: %v", err)
+	}
+	log.Printf("using OCR engine %s", cfg.OCREngine)
+
+	// initialize reranker, nil when disabled
+	reranker, err := rerank.New(cfg, provider)
+	if err != nil {
+		log.Fatalf("failed to initialize reranker: %v", err)
+	}
+	log.Printf("using reranker %s", cfg.Reranker)
+
+	// initialize tokenizer of the embedding model, for sizing chunks
+	bpe, err := tokenizer.ForModel(cfg.OpenAIEmbeddingModel)
+	if err != nil {
+		log.Fatalf("failed to initialize tokenizer: %v", err)
+	}
+
+	// initialize repository
+	userRepo := postgres.NewUserRepository(db)
+	docRepo := postgres.NewDocumentRepository(db)
+	chunkRepo := postgres.NewChunkRepository(db)
+	convRepo := postgres.NewConversationRepository(db)
+	msgRepo := postgres.NewMessageRepository(db)
+	jobRepo := postgres.NewJobRepository(db)
+
+	// initialize usecase
+	authUsecase := auth.NewAuthUsecase(userRepo, cfg.JWTSecret, cfg.JWTExpiration)
+	docUsecase := document.NewDocumentUsecase(
+		docRepo,
+		chunkRepo,
+		jobRepo,
+		blobStore,
+		embeddingClient,
+		chatClient,
+		chatClient,
+		chatClient,
+		reranker,
+		ocrEngine,
+		bpe,
+		document.ChunkingConfig{
+			ChunkSize:                    cfg.ChunkSize,
+			ChunkOverlap:                 cfg.ChunkOverlap,
+			MaxTokens:                    cfg.EmbeddingMaxTokens,
+			Strategy:                     cfg.ChunkingStrategy,
+			SemanticMinTokens:            cfg.SemanticMinTokens,
+			SemanticBreakpointPercentile: cfg.SemanticBreakpointPercentile,
+		},
+		document.SearchConfig{
+			Mode:            cfg.SearchMode,
+			VectorWeight:    cfg.HybridVectorWeight,
+			KeywordWeight:   cfg.HybridKeywordWeight,
+			RRFK:            cfg.HybridRRFK,
+			MMRLambda:       cfg.MMRLambda,
+			NeighbourChunks: cfg.NeighbourChunks,
+			Expansion:       cfg.QueryExpansion,
+			MultiQueryCount: cfg.MultiQueryCount,
+		},
+		cfg.TopKResults,
+		cfg.SimilarityThreshold,
+	)
+	// the old variables were in characters, about 4 per token
+	for _, name := range []string{"CHUNK_SIZE", "CHUNK_OVERLAP"} {
+		if os.Getenv(name) != "" {
+			log.Fatalf("%s is no longer used, set %s_TOKENS in tokens of the embedding model instead (about a quarter of the characters)", name, name)
+		}
+	}
+	if !docUsecase.SupportsChunkingStrategy(cfg.ChunkingStrategy) {
+		log.Fatalf("unknown chunking strategy %q, available: %s", cfg.ChunkingStrategy, strings.Join(docUsecase.ChunkingStrategies(), ", "))
+	}
+	if !docUsecase.SupportsSearchMode(cfg.SearchMode) {
+		log.Fatalf("unknown search mode %q, available: vector, hybrid", cfg.SearchMode)
+	}
+	if cfg.MMRLambda < 0 || cfg.MMRLambda > 1 {
+		log.Fatalf("MMR_LAMBDA must be between 0 and 1, got %v", cfg.MMRLambda)
+	}
+	if !docUsecase.SupportsExpansion(cfg.QueryExpansion) {
+		log.Fatalf("unknown query expansion %q, available: none, multi-query, hyde", cfg.QueryExpansion)
+	}
+	if cfg.MultiQueryCount < 1 {
+		log.Fatalf("MULTI_QUERY_COUNT must be at least 1, got %d", cfg.MultiQueryCount)
+	}
+	if cfg.NeighbourChunks < 0 || cfg.NeighbourChunks > document.MaxNeighbourChunks {
+		log.Fatalf("NEIGHBOUR_CHUNKS must be between 0 and %d, got %d", document.MaxNeighbourChunks, cfg.NeighbourChunks)
+	}
+	chatUsecase := chat.NewChatUsecase(
+		convRepo,
+		msgRepo,
+		docUsecase,
+		chatClient,
+		cfg.ChatHistoryLimit,
+	)
+
+	// start ingestion workers
+	if cfg.IngestionStaleAfter <= 0 {
+		log.Fatalf("INGESTION_STALE_AFTER must be positive, got %v", cfg.IngestionStaleAfter)
+	}
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	worker := ingestion.NewWorker(
+		jobRepo,
+		docRepo,
+		docUsecase,
+		cfg.IngestionWorkers,
+		cfg.IngestionMaxAttempts,
+		cfg.IngestionRetryBackoff,
+		cfg.IngestionPollInterval,
+		cfg.IngestionStaleAfter,
+	)
+	if err := worker.Start(workerCtx); err != nil {
+		log.Fatalf("failed to start ingestion workers: %v", err)
+	}
+
+	// initialize handler
+	authHandler := handler.NewAuthHandler(authUsecase)
+	docHandler := handler.NewDocumentHandler(docUsecase)
+	chatHandler := handler.NewChatHandler(chatUsecase)
+
+	// initialize fiber app
+	app := fiber.New()
+
+	// middleware for log request and response in terminal
+	app.Use(logger.New())
+
+	// Swagger route
+	app.Get("/swagger/*", fiberSwagger.WrapHandler)
+
+	// Public Routes
+	api := app.Group("/api")
+	api.Post("/auth/register", authHandler.Register)
+	api.Post("/auth/login", authHandler.Login)
+
+	// Protected Routes
+	protected := api.Group("", middleware.JWTAuth(cfg.JWTSecret))
+	protected.Get("/auth/me", authHandler.Me)
+
+	// document routes
+	protected.Post("/documents/upload", docHandler.Upload)
+	protected.Get("/documents", docHandler.List)
+	protected.Get("/documents/:id", docHandler.GetByID)
+	protected.Delete("/documents/:id", docHandler.Delete)
+	protected.Get("/documents/:id/download", docHandler.Download)
+	protected.Post("/documents/:id/reprocess", docHandler.Reprocess)
+	protected.Post("/documents/query", docHandler.Query)
+	protected.Post("/documents/query/stream", docHandler.QueryStream)
+
+	// admin routes
+	admin := protected.Group("/admin", middleware.RequireRole(entity.RoleAdmin))
+	admin.Post("/documents/reindex", docHandler.Reindex)
+
+	// chat routes
+	protected.Post("/chat/conversations", chatHandler.CreateConversation)
+	protected.Get("/chat/conversations", chatHandler.ListConversations)
+	protected.Get("/chat/conversations/:id", chatHandler.GetConversation)
+	protected.Patch("/chat/conversations/:id", chatHandler.RenameConversation)
+	protected.Delete("/chat/conversations/:id", chatHandler.DeleteConversation)
+	protected.Post("/chat/conversations/:id/messages", chatHandler.SendMessage)
+
+	// graceful shutdown, running jobs are handed back to the queue
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+
+		log.Println("shutting down")
+		stopWorkers()
+		worker.Wait()
+		app.Shutdown()
+	}()
+
+	//
+	//
+	//
+	// Start server
+	log.Printf("🚀 Server starting on port %d", cfg.Port)
+	log.Printf("📚 Swagger UI: http://localhost:%d/swagger/index.html", cfg.Port)
+	if err := app.Listen(fmt.Sprintf(":%d", cfg.Port)); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
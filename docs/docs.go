@@ -15,6 +15,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/documents/reindex": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue every document with a stored original file for reprocessing, e.g. after changing CHUNK_SIZE_TOKENS or the embedding model.\nDocuments that are already queued or have no stored file are skipped. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reindex all documents",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReindexResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/auth/login": {
             "post": {
                 "description": "Authenticate a user with email and password, returns a JWT token",
@@ -147,21 +184,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/documents": {
+        "/api/chat/conversations": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of documents for the authenticated user",
+                "description": "Get the authenticated user's conversations, most recently active first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Documents"
+                    "Chat"
                 ],
-                "summary": "List documents",
+                "summary": "List conversations",
                 "parameters": [
                     {
                         "type": "integer",
@@ -182,7 +219,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.ListDocumentsResponse"
+                            "$ref": "#/definitions/dto.ListConversationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
@@ -192,16 +235,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/documents/query": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Search documents using natural language and get AI-generated answer",
+                "description": "Start a new chat conversation. The title defaults to the first question asked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -209,25 +250,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Documents"
+                    "Chat"
                 ],
-                "summary": "Query documents with RAG",
+                "summary": "Create a conversation",
                 "parameters": [
                     {
-                        "description": "Query Request",
+                        "description": "Create Conversation Request",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.QueryDocumentRequest"
+                            "$ref": "#/definitions/dto.CreateConversationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.QueryDocumentResponse"
+                            "$ref": "#/definitions/dto.ConversationInfo"
                         }
                     },
                     "400": {
@@ -245,49 +285,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/documents/upload": {
-            "post": {
+        "/api/chat/conversations/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload a PDF or image file for processing",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "Get a conversation with all of its messages",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Documents"
+                    "Chat"
                 ],
-                "summary": "Upload a document",
+                "summary": "Get conversation detail",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "default": "PRIVATE",
-                        "description": "Visibility (PUBLIC or PRIVATE)",
-                        "name": "visibility",
-                        "in": "formData"
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UploadDocumentResponse"
+                            "$ref": "#/definitions/dto.ConversationDetail"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -299,27 +329,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/documents/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a single document's details",
+                "description": "Delete a conversation and all of its messages",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Documents"
+                    "Chat"
                 ],
-                "summary": "Get document by ID",
+                "summary": "Delete a conversation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Document ID",
+                        "description": "Conversation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -329,7 +357,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.DocumentInfo"
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
                     "404": {
@@ -346,34 +374,58 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a document by ID",
+                "description": "Change the title of a conversation",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Documents"
+                    "Chat"
                 ],
-                "summary": "Delete a document",
+                "summary": "Rename a conversation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Document ID",
+                        "description": "Conversation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Rename Conversation Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RenameConversationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.MessageResponse"
+                            "$ref": "#/definitions/dto.ConversationInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
@@ -384,91 +436,686 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.ChunkSource": {
-            "type": "object",
-            "properties": {
-                "chunkIndex": {
-                    "type": "integer"
-                },
-                "content": {
-                    "type": "string"
-                },
-                "documentId": {
-                    "type": "string"
-                },
-                "similarity": {
-                    "type": "number"
-                }
-            }
-        },
-        "dto.DocumentInfo": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "fileSize": {
-                    "type": "integer"
-                },
-                "filename": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "mimeType": {
-                    "type": "string"
-                },
-                "originalName": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "totalChunks": {
-                    "type": "integer"
-                },
-                "visibility": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Something went wrong"
-                }
-            }
         },
-        "dto.ListDocumentsResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.DocumentInfo"
+        "/api/chat/conversations/{id}/messages": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ask a question in a conversation. Retrieval runs over your own and public documents and the recent turns are sent to the LLM as history.\nFollow-up questions are rewritten into a standalone search query unless condenseQuery is false.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "Send a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Send Message Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SendMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
-                },
-                "meta": {
-                    "$ref": "#/definitions/dto.PaginationMeta"
                 }
             }
         },
-        "dto.LoginRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
+        "/api/documents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of documents for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "List documents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListDocumentsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/query": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search your own and public documents using natural language and get AI-generated answer.\nWhen history is sent, the query is first rewritten into a standalone search query unless condenseQuery is false.\nsearchMode hybrid also matches the words of the query and fuses both rankings, weighted by vectorWeight and keywordWeight.\nmmrLambda below 1 skips near-duplicate chunks in favour of more distinct sources.\nneighbourChunks adds that many chunks before and after every source to its content; touching windows are merged.\nexpansion multi-query also searches LLM paraphrases of the query, hyde a hypothetical answer; all rankings are fused and the texts returned as expansions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Query documents with RAG",
+                "parameters": [
+                    {
+                        "description": "Query Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryDocumentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/query/stream": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Same as /api/documents/query, but the answer is streamed as Server-Sent Events.\nEvents are sent in order: \"sources\" once, \"delta\" for every piece of the answer, then \"done\" with token usage.\nAn \"error\" event is sent instead if the query fails. Closing the connection cancels generation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Query documents with RAG, streaming the answer",
+                "parameters": [
+                    {
+                        "description": "Query Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "sources event; followed by dto.QueryStreamDeltaEvent and dto.QueryStreamDoneEvent",
+                        "schema": {
+                            "$ref": "#/definitions/dto.QueryStreamSourcesEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a PDF, DOCX, PPTX, plain text, Markdown, HTML, CSV or XLSX file for processing. PNG, JPEG and TIFF images are accepted when OCR is enabled, which also reads scanned PDF pages",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Upload a document",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "PRIVATE",
+                        "description": "Visibility (PUBLIC or PRIVATE)",
+                        "name": "visibility",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chunking strategy (fixed, recursive or semantic), the server default when empty",
+                        "name": "chunkingStrategy",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UploadDocumentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a single document's details",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Get document by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DocumentInfo"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a document by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Delete a document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the file exactly as it was uploaded",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Download the original file of a document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/documents/{id}/reprocess": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Process the stored original file again with the current chunking and embedding settings.\nA completed document stays searchable with its old chunks until the new ones are swapped in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documents"
+                ],
+                "summary": "Reprocess a document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReprocessDocumentResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ChatMessageInfo": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "USER"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MessageSourceInfo"
+                    }
+                }
+            }
+        },
+        "dto.ChatTurn": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "USER",
+                        "ASSISTANT"
+                    ],
+                    "example": "USER"
+                }
+            }
+        },
+        "dto.ChunkSource": {
+            "type": "object",
+            "properties": {
+                "chunkIndex": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "documentId": {
+                    "type": "string"
+                },
+                "endPage": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "endRow": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "endSlideNumber": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "headingPath": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Bab 1",
+                        "Pendahuluan"
+                    ]
+                },
+                "rerankScore": {
+                    "type": "number",
+                    "example": 0.91
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.0325
+                },
+                "sheetName": {
+                    "type": "string",
+                    "example": "Nilai"
+                },
+                "similarity": {
+                    "type": "number"
+                },
+                "slideNumber": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "startPage": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "startRow": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.ConversationDetail": {
+            "type": "object",
+            "properties": {
+                "conversation": {
+                    "$ref": "#/definitions/dto.ConversationInfo"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChatMessageInfo"
+                    }
+                }
+            }
+        },
+        "dto.ConversationInfo": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateConversationRequest": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "Statistika Dasar"
+                }
+            }
+        },
+        "dto.DocumentInfo": {
+            "type": "object",
+            "properties": {
+                "chunkingStrategy": {
+                    "type": "string",
+                    "example": "recursive"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mimeType": {
+                    "type": "string"
+                },
+                "originalName": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "totalChunks": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Something went wrong"
+                }
+            }
+        },
+        "dto.ListConversationsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConversationInfo"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                }
+            }
+        },
+        "dto.ListDocumentsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DocumentInfo"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/dto.PaginationMeta"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
                 },
                 "password": {
                     "type": "string",
@@ -522,6 +1169,61 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.MessageSourceInfo": {
+            "type": "object",
+            "properties": {
+                "chunkId": {
+                    "type": "string"
+                },
+                "chunkIndex": {
+                    "type": "integer"
+                },
+                "documentId": {
+                    "type": "string"
+                },
+                "endPage": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "endRow": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "endSlideNumber": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "headingPath": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Bab 1",
+                        "Pendahuluan"
+                    ]
+                },
+                "sheetName": {
+                    "type": "string",
+                    "example": "Nilai"
+                },
+                "similarity": {
+                    "type": "number"
+                },
+                "slideNumber": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "startPage": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "startRow": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
         "dto.PaginationMeta": {
             "type": "object",
             "properties": {
@@ -545,8 +1247,80 @@ const docTemplate = `{
                 "query"
             ],
             "properties": {
+                "condenseQuery": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdFrom": {
+                    "type": "string"
+                },
+                "createdTo": {
+                    "type": "string"
+                },
+                "documentIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expansion": {
+                    "description": "none, multi-query or hyde, the server default when empty",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "multi-query",
+                        "hyde"
+                    ],
+                    "example": "multi-query"
+                },
+                "history": {
+                    "description": "previous conversation turns, used to condense a follow-up question",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChatTurn"
+                    }
+                },
+                "keywordWeight": {
+                    "type": "number",
+                    "example": 1
+                },
+                "mimeTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "application/pdf"
+                    ]
+                },
+                "mmrLambda": {
+                    "description": "relevance against diversity of the sources, 1 turns MMR off",
+                    "type": "number",
+                    "example": 0.7
+                },
+                "neighbourChunks": {
+                    "description": "chunks before and after every hit added to its context, at most 5",
+                    "type": "integer",
+                    "example": 1
+                },
                 "query": {
                     "type": "string"
+                },
+                "searchMode": {
+                    "description": "vector or hybrid search, the server default when empty. The fusion\nweights only apply to hybrid searches.",
+                    "type": "string",
+                    "enum": [
+                        "vector",
+                        "hybrid"
+                    ],
+                    "example": "hybrid"
+                },
+                "uploadedBy": {
+                    "type": "string"
+                },
+                "vectorWeight": {
+                    "type": "number",
+                    "example": 1
                 }
             }
         },
@@ -556,9 +1330,41 @@ const docTemplate = `{
                 "answer": {
                     "type": "string"
                 },
+                "expansions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "query": {
+                    "type": "string"
+                },
+                "searchQuery": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChunkSource"
+                    }
+                }
+            }
+        },
+        "dto.QueryStreamSourcesEvent": {
+            "type": "object",
+            "properties": {
+                "expansions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "query": {
                     "type": "string"
                 },
+                "searchQuery": {
+                    "type": "string"
+                },
                 "sources": {
                     "type": "array",
                     "items": {
@@ -614,6 +1420,82 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReindexResponse": {
+            "type": "object",
+            "properties": {
+                "queued": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.RenameConversationRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "Statistika Dasar"
+                }
+            }
+        },
+        "dto.ReprocessDocumentResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SendMessageRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "condenseQuery": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Apa itu regresi linear?"
+                }
+            }
+        },
+        "dto.SendMessageResponse": {
+            "type": "object",
+            "properties": {
+                "assistantMessage": {
+                    "$ref": "#/definitions/dto.ChatMessageInfo"
+                },
+                "conversationId": {
+                    "type": "string"
+                },
+                "searchQuery": {
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChunkSource"
+                    }
+                },
+                "userMessage": {
+                    "$ref": "#/definitions/dto.ChatMessageInfo"
+                }
+            }
+        },
         "dto.UploadDocumentResponse": {
             "type": "object",
             "properties": {
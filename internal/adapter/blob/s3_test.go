@@ -0,0 +1,155 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// s3Server is an in-memory S3 answering like the real one, including 404s
+// for missing keys and 204 for deletes
+type s3Server struct {
+	*httptest.Server
+	mu      sync.Mutex
+	objects map[string][]byte
+	hosts   []string
+}
+
+func newS3Server(t *testing.T) *s3Server {
+	s := &s3Server{objects: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *s3Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hosts = append(s.hosts, r.Host)
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = body
+	case http.MethodGet, http.MethodHead:
+		data, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	server := newS3Server(t)
+
+	store, err := NewS3Store(server.URL, "us-east-1", "materi", "access", "secret", true)
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+
+	key := "dokumen/Catatan Kuliah #1.pdf"
+	if err := store.Put(ctx, key, []byte("isi file"), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := server.objects["/materi/"+key]; !ok {
+		t.Fatalf("stored keys %v, want the path style key /materi/%s", server.objects, key)
+	}
+
+	data, err := store.Get(ctx, key)
+	if err != nil || string(data) != "isi file" {
+		t.Errorf("Get = %q, %v, want the stored file", data, err)
+	}
+	exists, err := store.Exists(ctx, key)
+	if err != nil || !exists {
+		t.Errorf("Exists = %v, %v, want true", exists, err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	data, err = store.Get(ctx, key)
+	if err != nil || data != nil {
+		t.Errorf("Get of a deleted key = %q, %v, want nil, nil", data, err)
+	}
+	exists, err = store.Exists(ctx, key)
+	if err != nil || exists {
+		t.Errorf("Exists of a deleted key = %v, %v, want false", exists, err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+}
+
+func TestS3StoreReportsErrors(t *testing.T) {
+	server := newS3Server(t)
+
+	// a wrong key pair is rejected like an invalid signature would be
+	store, err := NewS3Store(server.URL, "us-east-1", "materi", "other", "secret", true)
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	err = store.Put(context.Background(), "file.txt", []byte("isi"), "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put returned %v, want the 403 of the server", err)
+	}
+	if _, err := store.Get(context.Background(), "file.txt"); err == nil {
+		t.Error("Get succeeded despite the 403")
+	}
+}
+
+// rewriteTransport sends every request to the test server, whatever its host
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = req.URL.Host
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestS3StoreVirtualHostStyle(t *testing.T) {
+	server := newS3Server(t)
+	target, _ := url.Parse(server.URL)
+
+	store, err := NewS3Store("https://s3.example.com", "auto", "materi", "access", "secret", false)
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	store.(*S3Store).client = &http.Client{Transport: rewriteTransport{target: target}}
+
+	if err := store.Put(context.Background(), "file.txt", []byte("isi"), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := server.objects["/file.txt"]; !ok || server.hosts[0] != "materi.s3.example.com" {
+		t.Errorf("stored %v on host %v, want /file.txt on materi.s3.example.com", server.objects, server.hosts)
+	}
+}
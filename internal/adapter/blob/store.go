@@ -0,0 +1,21 @@
+package blob
+
+import (
+	"fmt"
+	"strings"
+
+	"rag-api/internal/domain/repository"
+	"rag-api/pkg/config"
+)
+
+// New builds the blob store selected by cfg.BlobStorage
+func New(cfg *config.Config) (repository.BlobStore, error) {
+	switch strings.ToLower(cfg.BlobStorage) {
+	case "local":
+		return NewLocalStore(cfg.BlobLocalDir)
+	case "s3":
+		return NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3UsePathStyle)
+	default:
+		return nil, fmt.Errorf("unknown blob storage %q, available: local, s3", cfg.BlobStorage)
+	}
+}
@@ -0,0 +1,135 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"rag-api/internal/domain/entity"
+)
+
+// matches the "[Dokumen N - Similarity: x]" headers written by document.BuildContext
+var contextHeader = regexp.MustCompile(`(?m)^\[(Dokumen \d+)[^\]\n]*\]\n`)
+
+// excerptLength is the number of runes quoted from every cited document
+const excerptLength = 160
+
+// ChatClient answers from a fixed template that quotes and cites the given
+// context, so the whole RAG pipeline can run offline and deterministically
+type ChatClient struct{}
+
+func NewChatClient() *ChatClient {
+	return &ChatClient{}
+}
+
+// generate answer
+func (c *ChatClient) GenerateAnswer(ctx context.Context, query string, context string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return answer(query, context), nil
+}
+
+// generate answer as a stream, one word per delta
+func (c *ChatClient) GenerateAnswerStream(
+	ctx context.Context,
+	query string,
+	context string,
+	onDelta func(delta string) error,
+) (*entity.TokenUsage, error) {
+	text := answer(query, context)
+
+	words := strings.SplitAfter(text, " ")
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onDelta(word); err != nil {
+			return nil, err
+		}
+	}
+
+	promptTokens := len(strings.Fields(query)) + len(strings.Fields(context))
+	return &entity.TokenUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: len(words),
+		TotalTokens:      promptTokens + len(words),
+	}, nil
+}
+
+// generate answer with previous conversation turns, the history is ignored
+func (c *ChatClient) GenerateAnswerWithHistory(
+	ctx context.Context,
+	query string,
+	context string,
+	history []entity.Message,
+) (string, error) {
+	return c.GenerateAnswer(ctx, query, context)
+}
+
+// condense query by prefixing the previous user question, which is enough to
+// carry its keywords over to the embedding
+func (c *ChatClient) CondenseQuery(ctx context.Context, query string, history []entity.Message) (string, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == entity.MessageRoleUser {
+			return history[i].Content + " " + query, nil
+		}
+	}
+	return query, nil
+}
+
+// paraphrase query with fixed Indonesian phrasings
+func (c *ChatClient) Paraphrase(ctx context.Context, query string, n int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), "?")
+	paraphrases := []string{
+		"Penjelasan tentang " + query,
+		"Pengertian dan contoh " + query,
+		query + " dalam materi kuliah",
+	}
+	return paraphrases[:min(n, len(paraphrases))], nil
+}
+
+// write a hypothetical answer that repeats the query as a statement
+func (c *ChatClient) HypotheticalAnswer(ctx context.Context, query string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), "?")
+	return fmt.Sprintf("%s dijelaskan dalam materi kuliah beserta definisi, contoh, dan penerapannya.", query), nil
+}
+
+func answer(query, context string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Jawaban untuk \"%s\" berdasarkan dokumen:", query))
+
+	headers := contextHeader.FindAllStringSubmatchIndex(context, -1)
+	if len(headers) == 0 {
+		b.WriteString(" tidak ada konteks yang diberikan.")
+		return b.String()
+	}
+
+	for i, header := range headers {
+		end := len(context)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		label := context[header[2]:header[3]]
+		content := strings.Join(strings.Fields(context[header[1]:end]), " ")
+
+		b.WriteString(fmt.Sprintf("\n- [%s] %s", label, excerpt(content)))
+	}
+
+	return b.String()
+}
+
+func excerpt(text string) string {
+	runes := []rune(text)
+	if len(runes) <= excerptLength {
+		return text
+	}
+	return string(runes[:excerptLength]) + "..."
+}
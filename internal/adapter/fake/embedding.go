@@ -0,0 +1,85 @@
+package fake
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// EmbeddingClient produces deterministic embeddings without any network call.
+// Every word and character trigram of the text is hashed into one dimension,
+// so texts sharing words and word fragments end up close in cosine distance.
+type EmbeddingClient struct {
+	dimensions int
+}
+
+// NewEmbeddingClient creates a fake embedding client producing vectors of the given size
+func NewEmbeddingClient(dimensions int) *EmbeddingClient {
+	return &EmbeddingClient{dimensions: dimensions}
+}
+
+// generate batch embedding
+func (c *EmbeddingClient) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	vectors := make([]pgvector.Vector, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = pgvector.NewVector(c.embed(text))
+	}
+	return vectors, nil
+}
+
+func (c *EmbeddingClient) embed(text string) []float32 {
+	embedding := make([]float32, c.dimensions)
+
+	for _, word := range words(text) {
+		// whole words weigh more than their fragments
+		c.add(embedding, "w:"+word, 2)
+
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			c.add(embedding, "t:"+string(runes[i:i+3]), 1)
+		}
+	}
+
+	// normalize so cosine similarity only depends on direction
+	var norm float64
+	for _, v := range embedding {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return embedding
+	}
+	norm = math.Sqrt(norm)
+	for i := range embedding {
+		embedding[i] = float32(float64(embedding[i]) / norm)
+	}
+
+	return embedding
+}
+
+// words splits lowercase text at everything but letters and numbers
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// add hashes the feature to a dimension and a sign, which keeps unrelated
+// features from piling up in the same direction
+func (c *EmbeddingClient) add(embedding []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	index := int(sum % uint64(c.dimensions))
+	if sum&(1<<63) != 0 {
+		weight = -weight
+	}
+	embedding[index] += weight
+}
@@ -0,0 +1,42 @@
+package fake
+
+import (
+	"context"
+)
+
+// Reranker scores documents by the share of query words they contain, so
+// reranking can be tested offline and deterministically
+type Reranker struct{}
+
+func NewReranker() *Reranker {
+	return &Reranker{}
+}
+
+// rerank, scores are between 0 and 1
+func (r *Reranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	queryWords := words(query)
+	scores := make([]float64, len(documents))
+	if len(queryWords) == 0 {
+		return scores, nil
+	}
+
+	for i, document := range documents {
+		contained := make(map[string]bool)
+		for _, word := range words(document) {
+			contained[word] = true
+		}
+
+		matches := 0
+		for _, word := range queryWords {
+			if contained[word] {
+				matches++
+			}
+		}
+		scores[i] = float64(matches) / float64(len(queryWords))
+	}
+	return scores, nil
+}
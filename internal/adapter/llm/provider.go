@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/adapter/openai"
+	"rag-api/internal/usecase/chat"
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/config"
+)
+
+// ChatModel is everything the usecases need from a chat model
+type ChatModel interface {
+	document.StreamingChatService
+	document.QueryRewriter
+	document.QueryExpander
+	chat.ChatService
+}
+
+// Provider bundles the embedding and chat clients of one LLM backend
+type Provider struct {
+	Embedding document.EmbeddingService
+	Chat      ChatModel
+}
+
+// Factory builds a Provider from the application config
+type Factory func(cfg *config.Config) (*Provider, error)
+
+// EmbeddingDimensions matches the vector(1536) column of document_chunks
+const EmbeddingDimensions = 1536
+
+var factories = map[string]Factory{
+	"openai":            newOpenAIProvider,
+	"openai-compatible": newOpenAICompatibleProvider,
+	"ollama":            newOllamaProvider,
+	"fake":              newFakeProvider,
+}
+
+// Register adds or replaces the factory for a provider name
+func Register(name string, factory Factory) {
+	factories[strings.ToLower(name)] = factory
+}
+
+// New builds the provider selected by cfg.LLMProvider
+func New(cfg *config.Config) (*Provider, error) {
+	name := strings.ToLower(cfg.LLMProvider)
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q, available: %s", cfg.LLMProvider, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Names lists the registered provider names
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckEmbeddingDimensions embeds a probe text, so a model whose vectors don't
+// fit the embedding column fails at startup instead of on every upload
+func CheckEmbeddingDimensions(ctx context.Context, embedding document.EmbeddingService) error {
+	vectors, err := embedding.GenerateBatchEmbeddings(ctx, []string{"dimension check"})
+	if err != nil {
+		return fmt.Errorf("failed to embed a probe text: %w", err)
+	}
+	if len(vectors) != 1 {
+		return fmt.Errorf("embedding model returned %d vectors for 1 text", len(vectors))
+	}
+	if got := len(vectors[0].Slice()); got != EmbeddingDimensions {
+		return fmt.Errorf("embedding model returns %d dimensions, but the embedding column is vector(%d)", got, EmbeddingDimensions)
+	}
+	return nil
+}
+
+// official OpenAI API, LLM_BASE_URL can point it at a proxy
+func newOpenAIProvider(cfg *config.Config) (*Provider, error) {
+	if cfg.OpenAIKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY is required for the openai provider")
+	}
+	embeddingModel := withDefault(cfg.OpenAIEmbeddingModel, "text-embedding-3-small")
+	chatModel := withDefault(cfg.OpenAIChatModel, "gpt-4o-mini")
+
+	return &Provider{
+		Embedding: openai.NewEmbeddingClient(cfg.OpenAIKey, cfg.LLMBaseURL, embeddingModel),
+		Chat:      openai.NewChatClient(cfg.OpenAIKey, cfg.LLMBaseURL, chatModel),
+	}, nil
+}
+
+// any server speaking the OpenAI API, e.g. llama.cpp server or vLLM
+func newOpenAICompatibleProvider(cfg *config.Config) (*Provider, error) {
+	if cfg.LLMBaseURL == "" {
+		return nil, fmt.Errorf("LLM_BASE_URL is required for the openai-compatible provider")
+	}
+	if cfg.OpenAIEmbeddingModel == "" || cfg.OpenAIChatModel == "" {
+		return nil, fmt.Errorf("OPENAI_EMBEDDING_MODEL and OPENAI_CHAT_MODEL are required for the openai-compatible provider")
+	}
+
+	return &Provider{
+		Embedding: openai.NewEmbeddingClient(cfg.OpenAIKey, cfg.LLMBaseURL, cfg.OpenAIEmbeddingModel),
+		Chat:      openai.NewChatClient(cfg.OpenAIKey, cfg.LLMBaseURL, cfg.OpenAIChatModel),
+	}, nil
+}
+
+// local Ollama through its OpenAI-compatible endpoint
+func newOllamaProvider(cfg *config.Config) (*Provider, error) {
+	baseURL := withDefault(cfg.LLMBaseURL, "http://localhost:11434/v1")
+	// ollama ignores the key, but the client always sends one
+	apiKey := withDefault(cfg.OpenAIKey, "ollama")
+	chatModel := withDefault(cfg.OpenAIChatModel, "llama3.1")
+	// most local embedding models don't return the 1536 dimensions of the
+	// embedding column, so there is no default to silently fall back to
+	if cfg.OpenAIEmbeddingModel == "" {
+		return nil, fmt.Errorf("OPENAI_EMBEDDING_MODEL is required for the ollama provider, with a model returning %d dimensions", EmbeddingDimensions)
+	}
+
+	return &Provider{
+		Embedding: openai.NewEmbeddingClient(apiKey, baseURL, cfg.OpenAIEmbeddingModel),
+		Chat:      openai.NewChatClient(apiKey, baseURL, chatModel),
+	}, nil
+}
+
+// deterministic offline provider for tests and demos, no network needed
+func newFakeProvider(cfg *config.Config) (*Provider, error) {
+	return &Provider{
+		Embedding: fake.NewEmbeddingClient(EmbeddingDimensions),
+		Chat:      fake.NewChatClient(),
+	}, nil
+}
+
+func withDefault(val, defaultVal string) string {
+	if val != "" {
+		return val
+	}
+	return defaultVal
+}
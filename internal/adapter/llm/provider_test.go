@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/pkg/config"
+)
+
+// openAIServer fakes the parts of the OpenAI API the clients use, returning
+// embeddings of the given dimensions and a fixed answer
+type openAIServer struct {
+	*httptest.Server
+	dimensions int
+	models     []string
+}
+
+func newOpenAIServer(t *testing.T, dimensions int) *openAIServer {
+	s := &openAIServer{dimensions: dimensions}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/embeddings", s.embeddings)
+	mux.HandleFunc("/chat/completions", s.chatCompletions)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *openAIServer) embeddings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.models = append(s.models, req.Model)
+
+	data := make([]map[string]interface{}, len(req.Input))
+	for i := range req.Input {
+		embedding := make([]float32, s.dimensions)
+		embedding[i%s.dimensions] = 1
+		data[i] = map[string]interface{}{"object": "embedding", "index": i, "embedding": embedding}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "model": req.Model, "data": data})
+}
+
+func (s *openAIServer) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.models = append(s.models, req.Model)
+
+	if !req.Stream {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"object":  "chat.completion",
+			"model":   req.Model,
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": "Graf adalah himpunan simpul."}, "finish_reason": "stop"}},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, delta := range []string{"Graf adalah ", "himpunan simpul."} {
+		chunk, _ := json.Marshal(map[string]interface{}{
+			"object":  "chat.completion.chunk",
+			"model":   req.Model,
+			"choices": []map[string]interface{}{{"index": 0, "delta": map[string]string{"content": delta}}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+	}
+	usage, _ := json.Marshal(map[string]interface{}{
+		"object":  "chat.completion.chunk",
+		"model":   req.Model,
+		"choices": []interface{}{},
+		"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 4, "total_tokens": 14},
+	})
+	fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", usage)
+}
+
+func TestProviderContract(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.Config
+		embeddingModel string
+		chatModel      string
+	}{
+		{"openai", config.Config{LLMProvider: "openai", OpenAIKey: "sk-test"}, "text-embedding-3-small", "gpt-4o-mini"},
+		{"openai-compatible", config.Config{LLMProvider: "openai-compatible", OpenAIEmbeddingModel: "bge-m3", OpenAIChatModel: "qwen2.5"}, "bge-m3", "qwen2.5"},
+		{"ollama", config.Config{LLMProvider: "ollama", OpenAIEmbeddingModel: "mxbai-embed-large"}, "mxbai-embed-large", "llama3.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := newOpenAIServer(t, EmbeddingDimensions)
+			cfg := test.cfg
+			cfg.LLMBaseURL = server.URL
+
+			provider, err := New(&cfg)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			vectors, err := provider.Embedding.GenerateBatchEmbeddings(ctx, []string{"graf", "pohon"})
+			if err != nil {
+				t.Fatalf("GenerateBatchEmbeddings: %v", err)
+			}
+			if len(vectors) != 2 || len(vectors[1].Slice()) != EmbeddingDimensions || vectors[1].Slice()[1] != 1 {
+				t.Errorf("got %d vectors, want 2 of %d dimensions in input order", len(vectors), EmbeddingDimensions)
+			}
+			if err := CheckEmbeddingDimensions(ctx, provider.Embedding); err != nil {
+				t.Errorf("CheckEmbeddingDimensions: %v", err)
+			}
+
+			answer, err := provider.Chat.GenerateAnswer(ctx, "Apa itu graf?", "Graf adalah himpunan simpul.")
+			if err != nil || answer != "Graf adalah himpunan simpul." {
+				t.Errorf("GenerateAnswer = %q, %v", answer, err)
+			}
+
+			var streamed strings.Builder
+			usage, err := provider.Chat.GenerateAnswerStream(ctx, "Apa itu graf?", "Graf adalah himpunan simpul.", func(delta string) error {
+				streamed.WriteString(delta)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("GenerateAnswerStream: %v", err)
+			}
+			if streamed.String() != "Graf adalah himpunan simpul." || usage.TotalTokens != 14 {
+				t.Errorf("streamed %q using %d tokens, want the full answer using 14", streamed.String(), usage.TotalTokens)
+			}
+
+			history := []entity.Message{{Role: entity.MessageRoleUser, Content: "Apa itu pohon?"}}
+			if _, err := provider.Chat.CondenseQuery(ctx, "Dan graf?", history); err != nil {
+				t.Errorf("CondenseQuery: %v", err)
+			}
+
+			for _, model := range server.models {
+				if model != test.embeddingModel && model != test.chatModel {
+					t.Errorf("request for model %q, want %q or %q", model, test.embeddingModel, test.chatModel)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckEmbeddingDimensionsRejectsOtherSizes(t *testing.T) {
+	// nomic-embed-text returns 768 dimensions
+	server := newOpenAIServer(t, 768)
+	provider, err := New(&config.Config{LLMProvider: "ollama", LLMBaseURL: server.URL, OpenAIEmbeddingModel: "nomic-embed-text"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = CheckEmbeddingDimensions(context.Background(), provider.Embedding)
+	if err == nil || !strings.Contains(err.Error(), "768") {
+		t.Errorf("CheckEmbeddingDimensions returned %v, want a 768 dimensions error", err)
+	}
+}
+
+func TestProviderRequiredConfig(t *testing.T) {
+	tests := []config.Config{
+		{LLMProvider: "openai"},
+		{LLMProvider: "openai-compatible", OpenAIEmbeddingModel: "bge-m3", OpenAIChatModel: "qwen2.5"},
+		{LLMProvider: "openai-compatible", LLMBaseURL: "http://localhost:8081/v1"},
+		{LLMProvider: "ollama"},
+		{LLMProvider: "unknown"},
+	}
+	for _, cfg := range tests {
+		if _, err := New(&cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want a config error", cfg)
+		}
+	}
+}
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	provider, err := New(&config.Config{LLMProvider: "fake"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := CheckEmbeddingDimensions(ctx, provider.Embedding); err != nil {
+		t.Errorf("CheckEmbeddingDimensions: %v", err)
+	}
+
+	first, err := provider.Embedding.GenerateBatchEmbeddings(ctx, []string{"graf berarah"})
+	if err != nil {
+		t.Fatalf("GenerateBatchEmbeddings: %v", err)
+	}
+	second, _ := provider.Embedding.GenerateBatchEmbeddings(ctx, []string{"graf berarah"})
+	for i, v := range first[0].Slice() {
+		if second[0].Slice()[i] != v {
+			t.Fatal("the fake embedding is not deterministic")
+		}
+	}
+
+	var streamed strings.Builder
+	_, err = provider.Chat.GenerateAnswerStream(ctx, "Apa itu graf?", "Graf adalah himpunan simpul.", func(delta string) error {
+		streamed.WriteString(delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateAnswerStream: %v", err)
+	}
+	answer, err := provider.Chat.GenerateAnswer(ctx, "Apa itu graf?", "Graf adalah himpunan simpul.")
+	if err != nil || answer != streamed.String() {
+		t.Errorf("GenerateAnswer = %q, %v, want the streamed answer %q", answer, err, streamed.String())
+	}
+}
@@ -0,0 +1,24 @@
+package ocr
+
+import (
+	"fmt"
+	"strings"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/config"
+)
+
+// New builds the OCR engine selected by cfg.OCREngine, nil when OCR is disabled
+func New(cfg *config.Config) (document.OCREngine, error) {
+	switch strings.ToLower(cfg.OCREngine) {
+	case "", "none":
+		return nil, nil
+	case "tesseract":
+		return NewTesseractEngine(cfg.TesseractPath, cfg.PdftoppmPath, cfg.OCRLanguages, cfg.OCRDPI)
+	case "fake":
+		return fake.NewOCREngine(), nil
+	default:
+		return nil, fmt.Errorf("unknown OCR engine %q, available: none, tesseract, fake", cfg.OCREngine)
+	}
+}
@@ -0,0 +1,165 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"rag-api/internal/usecase/document"
+)
+
+// TesseractEngine runs the tesseract CLI. PDF pages are first rendered to an
+// image with pdftoppm from poppler-utils.
+type TesseractEngine struct {
+	tesseractPath string
+	pdftoppmPath  string
+	languages     string
+	dpi           int
+}
+
+// NewTesseractEngine checks that both binaries can be found. languages uses
+// tesseract's syntax, e.g. "ind+eng".
+func NewTesseractEngine(tesseractPath, pdftoppmPath, languages string, dpi int) (*TesseractEngine, error) {
+	tesseract, err := exec.LookPath(tesseractPath)
+	if err != nil {
+		return nil, fmt.Errorf("tesseract not found: %w", err)
+	}
+	pdftoppm, err := exec.LookPath(pdftoppmPath)
+	if err != nil {
+		return nil, fmt.Errorf("pdftoppm not found: %w", err)
+	}
+
+	return &TesseractEngine{
+		tesseractPath: tesseract,
+		pdftoppmPath:  pdftoppm,
+		languages:     languages,
+		dpi:           dpi,
+	}, nil
+}
+
+// recognize image
+func (e *TesseractEngine) RecognizeImage(ctx context.Context, image []byte) (*document.OCRResult, error) {
+	dir, err := os.MkdirTemp("", "rag-ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "image")
+	if err := os.WriteFile(path, image, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write image: %w", err)
+	}
+	return e.recognizeFile(ctx, path)
+}
+
+// recognize pdf page
+func (e *TesseractEngine) RecognizePDFPage(ctx context.Context, pdf []byte, page int) (*document.OCRResult, error) {
+	dir, err := os.MkdirTemp("", "rag-ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pdfPath := filepath.Join(dir, "document.pdf")
+	if err := os.WriteFile(pdfPath, pdf, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+
+	// -singlefile writes <root>.png without a page number suffix
+	pageNumber := strconv.Itoa(page)
+	imageRoot := filepath.Join(dir, "page")
+	if _, err := run(ctx, e.pdftoppmPath,
+		"-png", "-r", strconv.Itoa(e.dpi),
+		"-f", pageNumber, "-l", pageNumber,
+		"-singlefile", pdfPath, imageRoot,
+	); err != nil {
+		return nil, fmt.Errorf("failed to render page: %w", err)
+	}
+
+	return e.recognizeFile(ctx, imageRoot+".png")
+}
+
+func (e *TesseractEngine) recognizeFile(ctx context.Context, path string) (*document.OCRResult, error) {
+	args := []string{path, "stdout"}
+	if e.languages != "" {
+		args = append(args, "-l", e.languages)
+	}
+	// tsv output carries the confidence of every word
+	args = append(args, "tsv")
+
+	output, err := run(ctx, e.tesseractPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseTSV(output)
+}
+
+// parseTSV rebuilds the text from tesseract's word rows, a line break between
+// lines and a blank line between paragraphs. The confidence is the mean of
+// the word confidences.
+func parseTSV(output []byte) (*document.OCRResult, error) {
+	var text strings.Builder
+	var confidenceSum float64
+	words := 0
+	lastLine, lastParagraph := "", ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// level page block par line word left top width height conf text
+		fields := strings.SplitN(scanner.Text(), "\t", 12)
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		word := strings.TrimSpace(fields[11])
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if word == "" || err != nil || confidence < 0 {
+			continue
+		}
+
+		paragraph := strings.Join(fields[1:4], ".")
+		line := paragraph + "." + fields[4]
+		switch {
+		case text.Len() == 0:
+		case paragraph != lastParagraph:
+			text.WriteString("\n\n")
+		case line != lastLine:
+			text.WriteByte('\n')
+		default:
+			text.WriteByte(' ')
+		}
+		text.WriteString(word)
+		lastLine, lastParagraph = line, paragraph
+
+		confidenceSum += confidence
+		words++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tesseract output: %w", err)
+	}
+
+	result := &document.OCRResult{Text: text.String()}
+	if words > 0 {
+		result.Confidence = confidenceSum / float64(words) / 100
+	}
+	return result, nil
+}
+
+// run executes a command, returning its stdout and stderr in the error
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", filepath.Base(name), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -1,68 +1,247 @@
-package openai
-
-import (
-	"context"
-	"fmt"
-
-	openai "github.com/sashabaranov/go-openai"
-)
-
-type ChatClient struct {
-	client *openai.Client
-	model  string
-}
-
-func NewChatClient(apiKey, model string) *ChatClient {
-	return &ChatClient{
-		client: openai.NewClient(apiKey),
-		model:  model,
-	}
-}
-
-// generate answer
-func (c *ChatClient) GenerateAnswer(
-	ctx context.Context,
-	query string,
-	context string,
-) (string, error) {
-	systemPrompt := `Anda adalah asisten AI yang membantu menjawab pertanyaan berdasarkan dokumen yang diberikan.
-	
-	Instruksi:
-	1. Jawab pertanyaan HANYA berdasarkan konteks yang diberikan
-	2. Jika informasi tidak ada dalam konteks, katakan "Maaf, saya tidak menemukan informasi tersebut dalam dokumen"
-	3. Berikan jawaban yang jelas, ringkas, dan terstruktur
-	4. Gunakan bahasa Indonesia yang baik dan benar`
-
-	userPrompt := fmt.Sprintf(`Konteks dari dokumen:
-	%s
-
-	Pertanyaan: %s
-
-	Jawaban:`, context, query)
-
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: c.model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: systemPrompt,
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: userPrompt,
-			},
-		},
-		Temperature: 0.7,
-		MaxTokens:   700,
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("Failed to generate answer: %w", err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAi")
-	}
-
-	return resp.Choices[0].Message.Content, nil
-}
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"rag-api/internal/domain/entity"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type ChatClient struct {
+	client *openai.Client
+	model  string
+}
+
+func NewChatClient(apiKey, baseURL, model string) *ChatClient {
+	return &ChatClient{
+		client: newClient(apiKey, baseURL),
+		model:  model,
+	}
+}
+
+// generate answer
+func (c *ChatClient) GenerateAnswer(
+	ctx context.Context,
+	query string,
+	context string,
+) (string, error) {
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       c.model,
+		Messages:    answerMessages(query, context),
+		Temperature: 0.7,
+		MaxTokens:   700,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate answer: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAi")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// generate answer as a stream, calling onDelta for every content delta.
+// Generation stops as soon as ctx is cancelled or onDelta returns an error.
+func (c *ChatClient) GenerateAnswerStream(
+	ctx context.Context,
+	query string,
+	context string,
+	onDelta func(delta string) error,
+) (*entity.TokenUsage, error) {
+	stream, err := c.client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model:         c.model,
+		Messages:      answerMessages(query, context),
+		Temperature:   0.7,
+		MaxTokens:     700,
+		Stream:        true,
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate answer: %w", err)
+	}
+	defer stream.Close()
+
+	usage := &entity.TokenUsage{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return usage, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate answer: %w", err)
+		}
+
+		// the last chunk carries usage and no choices
+		if resp.Usage != nil {
+			usage.PromptTokens = resp.Usage.PromptTokens
+			usage.CompletionTokens = resp.Usage.CompletionTokens
+			usage.TotalTokens = resp.Usage.TotalTokens
+		}
+
+		for _, choice := range resp.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func answerMessages(query, context string) []openai.ChatCompletionMessage {
+	systemPrompt := `Anda adalah asisten AI yang membantu menjawab pertanyaan berdasarkan dokumen yang diberikan.
+	
+	Instruksi:
+	1. Jawab pertanyaan HANYA berdasarkan konteks yang diberikan
+	2. Jika informasi tidak ada dalam konteks, katakan "Maaf, saya tidak menemukan informasi tersebut dalam dokumen"
+	3. Berikan jawaban yang jelas, ringkas, dan terstruktur
+	4. Gunakan bahasa Indonesia yang baik dan benar`
+
+	userPrompt := fmt.Sprintf(`Konteks dari dokumen:
+	%s
+
+	Pertanyaan: %s
+
+	Jawaban:`, context, query)
+
+	return []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemPrompt,
+		},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: userPrompt,
+		},
+	}
+}
+
+// generate answer with previous conversation turns
+func (c *ChatClient) GenerateAnswerWithHistory(
+	ctx context.Context,
+	query string,
+	context string,
+	history []entity.Message,
+) (string, error) {
+	systemPrompt := `Anda adalah asisten AI yang membantu menjawab pertanyaan berdasarkan dokumen yang diberikan.
+
+	Instruksi:
+	1. Jawab pertanyaan HANYA berdasarkan konteks yang diberikan
+	2. Gunakan riwayat percakapan untuk memahami pertanyaan lanjutan
+	3. Jika informasi tidak ada dalam konteks, katakan "Maaf, saya tidak menemukan informasi tersebut dalam dokumen"
+	4. Berikan jawaban yang jelas, ringkas, dan terstruktur
+	5. Gunakan bahasa Indonesia yang baik dan benar`
+
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemPrompt,
+		},
+	}
+
+	// add conversation history
+	for _, msg := range history {
+		role := openai.ChatMessageRoleUser
+		if msg.Role == entity.MessageRoleAssistant {
+			role = openai.ChatMessageRoleAssistant
+		}
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    role,
+			Content: msg.Content,
+		})
+	}
+
+	userPrompt := fmt.Sprintf(`Konteks dari dokumen:
+	%s
+
+	Pertanyaan: %s
+
+	Jawaban:`, context, query)
+
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: userPrompt,
+	})
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: 0.7,
+		MaxTokens:   700,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate answer: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAi")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// condense a follow-up question and the conversation history into a standalone search query
+func (c *ChatClient) CondenseQuery(
+	ctx context.Context,
+	query string,
+	history []entity.Message,
+) (string, error) {
+	systemPrompt := `Tugas Anda adalah menulis ulang pertanyaan terakhir pengguna menjadi satu query pencarian yang berdiri sendiri.
+
+	Instruksi:
+	1. Gunakan riwayat percakapan untuk melengkapi rujukan seperti "itu", "yang kedua", atau "metode tersebut"
+	2. Pertahankan bahasa yang digunakan pengguna
+	3. Jangan menjawab pertanyaan, tulis HANYA query pencariannya tanpa penjelasan`
+
+	var transcript strings.Builder
+	for _, msg := range history {
+		role := "Pengguna"
+		if msg.Role == entity.MessageRoleAssistant {
+			role = "Asisten"
+		}
+		transcript.WriteString(fmt.Sprintf("%s: %s\n", role, msg.Content))
+	}
+
+	userPrompt := fmt.Sprintf(`Riwayat percakapan:
+	%s
+
+	Pertanyaan terakhir: %s
+
+	Query pencarian:`, transcript.String(), query)
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userPrompt,
+			},
+		},
+		Temperature: 0,
+		MaxTokens:   100,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to condense query: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAi")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
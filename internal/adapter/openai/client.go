@@ -0,0 +1,15 @@
+package openai
+
+import (
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// newClient creates an OpenAI API client. An empty baseURL uses the official
+// API, otherwise requests go to that OpenAI-compatible server (llama.cpp, Ollama, ...)
+func newClient(apiKey, baseURL string) *openai.Client {
+	config := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	return openai.NewClientWithConfig(config)
+}
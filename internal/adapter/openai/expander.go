@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// paraphrase query into up to n search queries with different wording
+func (c *ChatClient) Paraphrase(ctx context.Context, query string, n int) ([]string, error) {
+	systemPrompt := fmt.Sprintf(`Tugas Anda adalah menulis %d variasi query pencarian untuk pertanyaan pengguna, agar dokumen yang relevan lebih mudah ditemukan.
+
+	Instruksi:
+	1. Setiap variasi memakai kata-kata atau sudut pandang yang berbeda, misalnya sinonim, istilah teknis, atau singkatan yang dijabarkan
+	2. Pertahankan maksud dan bahasa pertanyaan
+	3. Jangan menjawab pertanyaan, jawab HANYA dengan JSON {"queries": [...]}`, n)
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: query,
+			},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Temperature:    0.7,
+		MaxTokens:      60 * n,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to paraphrase query: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAi")
+	}
+
+	var result struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+		return nil, fmt.Errorf("invalid paraphrase response: %w", err)
+	}
+	if len(result.Queries) > n {
+		result.Queries = result.Queries[:n]
+	}
+	return result.Queries, nil
+}
+
+// write a hypothetical answer to query, for HyDE retrieval
+func (c *ChatClient) HypotheticalAnswer(ctx context.Context, query string) (string, error) {
+	systemPrompt := `Tugas Anda adalah menulis satu paragraf singkat yang menjawab pertanyaan pengguna, seperti kutipan dari materi kuliah.
+
+	Instruksi:
+	1. Tulis dengan gaya dan istilah yang biasa dipakai buku teks atau slide kuliah
+	2. Gunakan bahasa yang sama dengan pertanyaan
+	3. Jika tidak yakin, tetap tulis jawaban yang paling masuk akal tanpa menyebut ketidakpastian`
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: query,
+			},
+		},
+		Temperature: 0,
+		MaxTokens:   250,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to write hypothetical answer: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAi")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
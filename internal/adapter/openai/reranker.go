@@ -0,0 +1,70 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Rerank asks the chat model to score every document from 0 to 10 in one
+// request. Scores are returned between 0 and 1.
+func (c *ChatClient) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	systemPrompt := `Tugas Anda adalah menilai seberapa relevan setiap potongan dokumen untuk menjawab pertanyaan pengguna.
+
+	Instruksi:
+	1. Beri setiap potongan skor 0 sampai 10: 10 berarti langsung menjawab pertanyaan, 0 berarti tidak berhubungan
+	2. Nilai setiap potongan sendiri-sendiri, jangan menjawab pertanyaannya
+	3. Jawab HANYA dengan JSON {"scores": [...]} berisi satu skor untuk setiap potongan, sesuai urutannya`
+
+	var passages strings.Builder
+	for i, document := range documents {
+		passages.WriteString(fmt.Sprintf("[Potongan %d]\n%s\n\n", i+1, document))
+	}
+
+	userPrompt := fmt.Sprintf(`Pertanyaan: %s
+
+	%sJumlah potongan: %d`, query, passages.String(), len(documents))
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userPrompt,
+			},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Temperature:    0,
+		MaxTokens:      10 + 5*len(documents),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAi")
+	}
+
+	var result struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+		return nil, fmt.Errorf("invalid rerank response: %w", err)
+	}
+	if len(result.Scores) != len(documents) {
+		return nil, fmt.Errorf("got %d rerank scores, expected %d", len(result.Scores), len(documents))
+	}
+
+	for i := range result.Scores {
+		result.Scores[i] /= 10
+	}
+	return result.Scores, nil
+}
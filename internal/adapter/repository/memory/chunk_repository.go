@@ -0,0 +1,356 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+)
+
+type chunkRepository struct {
+	db *DB
+}
+
+func NewChunkRepository(db *DB) repository.ChunkRepository {
+	return &chunkRepository{db: db}
+}
+
+// create chunk
+func (r *chunkRepository) Create(ctx context.Context, chunk *entity.DocumentChunk) error {
+	chunks := []entity.DocumentChunk{*chunk}
+	if err := r.CreateBatch(ctx, chunks); err != nil {
+		return err
+	}
+	*chunk = chunks[0]
+	return nil
+}
+
+// CreateBatch creates multiple chunks, all or nothing like the postgres transaction
+func (r *chunkRepository) CreateBatch(ctx context.Context, chunks []entity.DocumentChunk) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.insert(chunks, "")
+}
+
+// ReplaceByDocumentID swaps all chunks of a document under one lock, so
+// searches see either the old chunks or the new ones
+func (r *chunkRepository) ReplaceByDocumentID(ctx context.Context, documentID string, chunks []entity.DocumentChunk) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.insert(chunks, documentID)
+}
+
+// insert checks constraints before inserting anything. Chunks of replaced are
+// deleted first, once the new ones are known to be valid. db.mu must be held.
+func (r *chunkRepository) insert(chunks []entity.DocumentChunk, replaced string) error {
+	taken := make(map[string]map[int]bool)
+	for _, existing := range r.db.chunks {
+		if existing.DocumentID == replaced {
+			continue
+		}
+		if taken[existing.DocumentID] == nil {
+			taken[existing.DocumentID] = make(map[int]bool)
+		}
+		taken[existing.DocumentID][existing.ChunkIndex] = true
+	}
+	for _, chunk := range chunks {
+		if _, ok := r.db.documents[chunk.DocumentID]; !ok {
+			return foreignKeyError("document_chunks", "documentId", chunk.DocumentID)
+		}
+		if taken[chunk.DocumentID][chunk.ChunkIndex] {
+			return uniqueError("document_chunks_documentId_chunkIndex_key")
+		}
+		if taken[chunk.DocumentID] == nil {
+			taken[chunk.DocumentID] = make(map[int]bool)
+		}
+		taken[chunk.DocumentID][chunk.ChunkIndex] = true
+	}
+
+	if replaced != "" {
+		for id, chunk := range r.db.chunks {
+			if chunk.DocumentID == replaced {
+				delete(r.db.chunks, id)
+				delete(r.db.order, id)
+			}
+		}
+	}
+
+	for i := range chunks {
+		chunks[i].ID = uuid.New().String()
+		chunks[i].CreatedAt = time.Now()
+
+		r.db.chunks[chunks[i].ID] = chunks[i]
+		r.db.inserted(chunks[i].ID)
+	}
+
+	return nil
+}
+
+// SearchSimilar compares the embedding against every chunk. It applies the
+// same access rules, filters, threshold and ordering as the postgres query.
+func (r *chunkRepository) SearchSimilar(ctx context.Context, embedding pgvector.Vector, filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	chunks, err := r.similar(embedding, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.TopK >= 0 && len(chunks) > filter.TopK {
+		chunks = chunks[:filter.TopK]
+	}
+
+	return chunks, nil
+}
+
+// SearchHybrid fuses the vector ranking with a keyword ranking like the
+// postgres query. Words are matched exactly, ignoring case, and ranked by how
+// often they occur relative to the chunk length, close to ts_rank_cd.
+func (r *chunkRepository) SearchHybrid(ctx context.Context, embedding pgvector.Vector, search repository.HybridSearch, filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	vectorRanked, err := r.similar(embedding, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectorRanked) > search.Candidates {
+		vectorRanked = vectorRanked[:search.Candidates]
+	}
+
+	fused := make(map[string]*entity.SimilarChunk)
+	for i, chunk := range vectorRanked {
+		chunk.Score = search.VectorWeight / float64(search.RRFK+i+1)
+		fused[chunk.ID] = &chunk
+	}
+
+	keywordRanked := r.keywordRanked(search.Query, filter)
+	if len(keywordRanked) > search.Candidates {
+		keywordRanked = keywordRanked[:search.Candidates]
+	}
+	for i, chunk := range keywordRanked {
+		score := search.KeywordWeight / float64(search.RRFK+i+1)
+		if existing, ok := fused[chunk.ID]; ok {
+			existing.Score += score
+			continue
+		}
+		similarity, _ := cosineSimilarity(embedding.Slice(), chunk.Embedding.Slice())
+		fused[chunk.ID] = &entity.SimilarChunk{
+			DocumentChunk: chunk,
+			Similarity:    similarity,
+			Score:         score,
+		}
+	}
+
+	chunks := make([]entity.SimilarChunk, 0, len(fused))
+	for _, chunk := range fused {
+		chunks = append(chunks, *chunk)
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].Score != chunks[j].Score {
+			return chunks[i].Score > chunks[j].Score
+		}
+		if chunks[i].Similarity != chunks[j].Similarity {
+			return chunks[i].Similarity > chunks[j].Similarity
+		}
+		return r.db.order[chunks[i].ID] < r.db.order[chunks[j].ID]
+	})
+
+	if filter.TopK >= 0 && len(chunks) > filter.TopK {
+		chunks = chunks[:filter.TopK]
+	}
+
+	return chunks, nil
+}
+
+// similar returns every visible chunk above the threshold, most similar
+// first. db.mu must be held.
+func (r *chunkRepository) similar(embedding pgvector.Vector, filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+	var chunks []entity.SimilarChunk
+	for _, chunk := range r.db.chunks {
+		doc := r.db.documents[chunk.DocumentID]
+		if !matchesFilter(doc, filter) {
+			continue
+		}
+
+		if stored := len(chunk.Embedding.Slice()); stored > 0 && stored != len(embedding.Slice()) {
+			return nil, fmt.Errorf("different vector dimensions %d and %d", len(embedding.Slice()), stored)
+		}
+
+		// NULL or zero embeddings never match, like NaN distances in pgvector
+		similarity, ok := cosineSimilarity(embedding.Slice(), chunk.Embedding.Slice())
+		if !ok || similarity < filter.Threshold {
+			continue
+		}
+
+		chunks = append(chunks, entity.SimilarChunk{
+			DocumentChunk: chunk,
+			Similarity:    similarity,
+		})
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].Similarity != chunks[j].Similarity {
+			return chunks[i].Similarity > chunks[j].Similarity
+		}
+		return r.db.order[chunks[i].ID] < r.db.order[chunks[j].ID]
+	})
+
+	return chunks, nil
+}
+
+// keywordRanked returns every visible chunk containing a word of query, best
+// match first. db.mu must be held.
+func (r *chunkRepository) keywordRanked(query string, filter repository.ChunkSearchFilter) []entity.DocumentChunk {
+	terms := make(map[string]bool)
+	for _, word := range words(query) {
+		terms[word] = true
+	}
+
+	var chunks []entity.DocumentChunk
+	ranks := make(map[string]float64)
+	for _, chunk := range r.db.chunks {
+		// chunks without an embedding are skipped, like NULL ones in postgres
+		if !matchesFilter(r.db.documents[chunk.DocumentID], filter) || len(chunk.Embedding.Slice()) == 0 {
+			continue
+		}
+
+		content := words(chunk.Content)
+		matches := 0
+		for _, word := range content {
+			if terms[word] {
+				matches++
+			}
+		}
+		if matches == 0 {
+			continue
+		}
+
+		chunks = append(chunks, chunk)
+		ranks[chunk.ID] = float64(matches) / (1 + math.Log(float64(len(content))))
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		if ranks[chunks[i].ID] != ranks[chunks[j].ID] {
+			return ranks[chunks[i].ID] > ranks[chunks[j].ID]
+		}
+		return r.db.order[chunks[i].ID] < r.db.order[chunks[j].ID]
+	})
+	return chunks
+}
+
+// words splits text into lowercase words of letters and digits, like the
+// simple text search configuration
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ListByRanges returns the chunks within any of the ranges, ordered by
+// document and chunk index, of the documents the user may search
+func (r *chunkRepository) ListByRanges(ctx context.Context, userID string, ranges []repository.ChunkRange) ([]entity.DocumentChunk, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var chunks []entity.DocumentChunk
+	for _, chunk := range r.db.chunks {
+		if !matchesFilter(r.db.documents[chunk.DocumentID], repository.ChunkSearchFilter{UserID: userID}) {
+			continue
+		}
+		for _, chunkRange := range ranges {
+			if chunk.DocumentID == chunkRange.DocumentID && chunk.ChunkIndex >= chunkRange.From && chunk.ChunkIndex <= chunkRange.To {
+				chunks = append(chunks, chunk)
+				break
+			}
+		}
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].DocumentID != chunks[j].DocumentID {
+			return chunks[i].DocumentID < chunks[j].DocumentID
+		}
+		return chunks[i].ChunkIndex < chunks[j].ChunkIndex
+	})
+
+	return chunks, nil
+}
+
+// DeleteByDocumentID deletes all chunks containing the document ID
+func (r *chunkRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, chunk := range r.db.chunks {
+		if chunk.DocumentID == documentID {
+			delete(r.db.chunks, id)
+			delete(r.db.order, id)
+		}
+	}
+	return nil
+}
+
+func matchesFilter(doc entity.Document, filter repository.ChunkSearchFilter) bool {
+	if doc.Status != entity.StatusCompleted {
+		return false
+	}
+	if doc.UserID != filter.UserID && doc.Visibility != entity.VisibilityPublic {
+		return false
+	}
+	if len(filter.DocumentIDs) > 0 && !contains(filter.DocumentIDs, doc.ID) {
+		return false
+	}
+	if len(filter.MimeTypes) > 0 && !contains(filter.MimeTypes, doc.MimeType) {
+		return false
+	}
+	if filter.UploadedBy != "" && doc.UserID != filter.UploadedBy {
+		return false
+	}
+	if filter.CreatedFrom != nil && doc.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && doc.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// cosineSimilarity is 1 - the pgvector <=> distance
+func cosineSimilarity(a, b []float32) (float64, bool) {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0, false
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
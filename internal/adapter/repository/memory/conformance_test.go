@@ -0,0 +1,22 @@
+package memory_test
+
+import (
+	"testing"
+
+	"rag-api/internal/adapter/repository/memory"
+	"rag-api/internal/adapter/repository/repositorytest"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := memory.NewDB()
+		return repositorytest.Repositories{
+			Users:         memory.NewUserRepository(db),
+			Documents:     memory.NewDocumentRepository(db),
+			Chunks:        memory.NewChunkRepository(db),
+			Jobs:          memory.NewJobRepository(db),
+			Conversations: memory.NewConversationRepository(db),
+			Messages:      memory.NewMessageRepository(db),
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type conversationRepository struct {
+	db *DB
+}
+
+func NewConversationRepository(db *DB) repository.ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+// create conversation
+func (r *conversationRepository) Create(ctx context.Context, conv *entity.Conversation) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[conv.UserID]; !ok {
+		return foreignKeyError("conversations", "userId", conv.UserID)
+	}
+
+	conv.ID = uuid.New().String()
+	conv.CreatedAt = time.Now()
+	conv.UpdatedAt = time.Now()
+
+	r.db.conversations[conv.ID] = *conv
+	r.db.inserted(conv.ID)
+	return nil
+}
+
+// find conversation by id and user id
+func (r *conversationRepository) FindByIDAndUserID(ctx context.Context, id, userID string) (*entity.Conversation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	conv, ok := r.db.conversations[id]
+	if !ok || conv.UserID != userID {
+		return nil, nil
+	}
+	return &conv, nil
+}
+
+// list conversation, most recently active first
+func (r *conversationRepository) List(ctx context.Context, userID string, page, limit int) ([]entity.Conversation, int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var convs []entity.Conversation
+	for _, conv := range r.db.conversations {
+		if conv.UserID == userID {
+			convs = append(convs, conv)
+		}
+	}
+
+	sort.Slice(convs, func(i, j int) bool {
+		if !convs[i].UpdatedAt.Equal(convs[j].UpdatedAt) {
+			return convs[i].UpdatedAt.After(convs[j].UpdatedAt)
+		}
+		return r.db.order[convs[i].ID] > r.db.order[convs[j].ID]
+	})
+
+	return paginate(convs, page, limit), len(convs), nil
+}
+
+// update title and bump updatedAt
+func (r *conversationRepository) Update(ctx context.Context, conv *entity.Conversation) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	conv.UpdatedAt = time.Now()
+	if existing, ok := r.db.conversations[conv.ID]; ok {
+		existing.Title = conv.Title
+		existing.UpdatedAt = conv.UpdatedAt
+		r.db.conversations[conv.ID] = existing
+	}
+	return nil
+}
+
+// delete conversation, messages are removed by cascade
+func (r *conversationRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.deleteConversation(id)
+	return nil
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+
+	"rag-api/internal/domain/entity"
+)
+
+// DB is an in-memory stand-in for the Postgres database, shared by the memory
+// repositories the same way the postgres ones share a *sqlx.DB. It enforces the
+// unique indexes and foreign keys of the migrations, including cascading deletes.
+type DB struct {
+	mu sync.RWMutex
+
+	users         map[string]entity.User
+	documents     map[string]entity.Document
+	chunks        map[string]entity.DocumentChunk
+	conversations map[string]entity.Conversation
+	messages      map[string]entity.Message
+	jobs          map[string]entity.IngestionJob
+
+	// insertion order, used to break ties when sorting by timestamp
+	seq   int64
+	order map[string]int64
+}
+
+func NewDB() *DB {
+	return &DB{
+		users:         make(map[string]entity.User),
+		documents:     make(map[string]entity.Document),
+		chunks:        make(map[string]entity.DocumentChunk),
+		conversations: make(map[string]entity.Conversation),
+		messages:      make(map[string]entity.Message),
+		jobs:          make(map[string]entity.IngestionJob),
+		order:         make(map[string]int64),
+	}
+}
+
+// inserted records the insertion order of a new row, db.mu must be held
+func (db *DB) inserted(id string) {
+	db.seq++
+	db.order[id] = db.seq
+}
+
+// deleteDocument removes a document with its chunks and jobs, db.mu must be held
+func (db *DB) deleteDocument(id string) {
+	delete(db.documents, id)
+	delete(db.order, id)
+	for chunkID, chunk := range db.chunks {
+		if chunk.DocumentID == id {
+			delete(db.chunks, chunkID)
+			delete(db.order, chunkID)
+		}
+	}
+	for jobID, job := range db.jobs {
+		if job.DocumentID == id {
+			delete(db.jobs, jobID)
+			delete(db.order, jobID)
+		}
+	}
+}
+
+// deleteConversation removes a conversation and its messages, db.mu must be held
+func (db *DB) deleteConversation(id string) {
+	delete(db.conversations, id)
+	delete(db.order, id)
+	for msgID, msg := range db.messages {
+		if msg.ConversationID == id {
+			delete(db.messages, msgID)
+			delete(db.order, msgID)
+		}
+	}
+}
+
+func foreignKeyError(table, column, value string) error {
+	return fmt.Errorf("insert or update on table %q violates foreign key constraint: %s %q does not exist", table, column, value)
+}
+
+func uniqueError(index string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", index)
+}
+
+// paginate mirrors LIMIT/OFFSET
+func paginate[T any](rows []T, page, limit int) []T {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return nil
+	}
+	end := len(rows)
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return rows[offset:end]
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type documentRepository struct {
+	db *DB
+}
+
+func NewDocumentRepository(db *DB) repository.DocumentRepository {
+	return &documentRepository{db: db}
+}
+
+// create document
+func (r *documentRepository) Create(ctx context.Context, doc *entity.Document) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[doc.UserID]; !ok {
+		return foreignKeyError("documents", "userId", doc.UserID)
+	}
+
+	doc.ID = uuid.New().String()
+	doc.CreatedAt = time.Now()
+	doc.UpdatedAt = time.Now()
+
+	r.db.documents[doc.ID] = *doc
+	r.db.inserted(doc.ID)
+	return nil
+}
+
+// find document by id
+func (r *documentRepository) FindByID(ctx context.Context, id string) (*entity.Document, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	doc, ok := r.db.documents[id]
+	if !ok {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+// find document by id and user id
+func (r *documentRepository) FindByIDAndUserID(ctx context.Context, id, userID string) (*entity.Document, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	doc, ok := r.db.documents[id]
+	if !ok || doc.UserID != userID {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+// list document, newest first
+func (r *documentRepository) List(ctx context.Context, userID string, page, limit int) ([]entity.Document, int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var docs []entity.Document
+	for _, doc := range r.db.documents {
+		if doc.UserID == userID {
+			docs = append(docs, doc)
+		}
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		if !docs[i].CreatedAt.Equal(docs[j].CreatedAt) {
+			return docs[i].CreatedAt.After(docs[j].CreatedAt)
+		}
+		return r.db.order[docs[i].ID] > r.db.order[docs[j].ID]
+	})
+
+	return paginate(docs, page, limit), len(docs), nil
+}
+
+// list the documents of every user, oldest first
+func (r *documentRepository) ListAll(ctx context.Context) ([]entity.Document, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	docs := make([]entity.Document, 0, len(r.db.documents))
+	for _, doc := range r.db.documents {
+		docs = append(docs, doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		if !docs[i].CreatedAt.Equal(docs[j].CreatedAt) {
+			return docs[i].CreatedAt.Before(docs[j].CreatedAt)
+		}
+		return r.db.order[docs[i].ID] < r.db.order[docs[j].ID]
+	})
+
+	return docs, nil
+}
+
+// update status
+func (r *documentRepository) UpdateStatus(ctx context.Context, id string, status entity.DocumentStatus) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if doc, ok := r.db.documents[id]; ok {
+		doc.Status = status
+		doc.UpdatedAt = time.Now()
+		r.db.documents[id] = doc
+	}
+	return nil
+}
+
+// update total chunks
+func (r *documentRepository) UpdateTotalChunks(ctx context.Context, id string, totalChunks int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if doc, ok := r.db.documents[id]; ok {
+		doc.TotalChunks = totalChunks
+		doc.UpdatedAt = time.Now()
+		r.db.documents[id] = doc
+	}
+	return nil
+}
+
+// delete document, its chunks are removed by cascade
+func (r *documentRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.deleteDocument(id)
+	return nil
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type jobRepository struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) repository.JobRepository {
+	return &jobRepository{db: db}
+}
+
+// enqueue job, due immediately
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.IngestionJob) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.hasActive(job.DocumentID) {
+		return uniqueError("ingestion_jobs_documentId_active_key")
+	}
+	return r.insert(job)
+}
+
+// EnqueueIfIdle checks and inserts under one lock, like the partial unique
+// index of active jobs in postgres
+func (r *jobRepository) EnqueueIfIdle(ctx context.Context, job *entity.IngestionJob) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.hasActive(job.DocumentID) {
+		return false, nil
+	}
+	if err := r.insert(job); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// insert job, db.mu must be held
+func (r *jobRepository) insert(job *entity.IngestionJob) error {
+	if _, ok := r.db.documents[job.DocumentID]; !ok {
+		return foreignKeyError("ingestion_jobs", "documentId", job.DocumentID)
+	}
+
+	job.ID = uuid.New().String()
+	job.Status = entity.JobPending
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+	job.RunAt = job.CreatedAt
+
+	r.db.jobs[job.ID] = *job
+	r.db.inserted(job.ID)
+	return nil
+}
+
+// hasActive checks for a pending or running job of the document. db.mu must
+// be held.
+func (r *jobRepository) hasActive(documentID string) bool {
+	for _, job := range r.db.jobs {
+		if job.DocumentID == documentID && (job.Status == entity.JobPending || job.Status == entity.JobRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+// claim the oldest due job, the lock makes it exclusive like SKIP LOCKED
+func (r *jobRepository) Claim(ctx context.Context) (*entity.IngestionJob, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var claimed *entity.IngestionJob
+	for _, job := range r.db.jobs {
+		if job.Status != entity.JobPending || job.RunAt.After(now) {
+			continue
+		}
+		if claimed == nil || job.RunAt.Before(claimed.RunAt) ||
+			(job.RunAt.Equal(claimed.RunAt) && r.db.order[job.ID] < r.db.order[claimed.ID]) {
+			claimed = &job
+		}
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+
+	claimed.Status = entity.JobRunning
+	claimed.Attempts++
+	claimed.LockedAt = &now
+	claimed.UpdatedAt = now
+	r.db.jobs[claimed.ID] = *claimed
+	return claimed, nil
+}
+
+// refresh the lock of a running job
+func (r *jobRepository) Heartbeat(ctx context.Context, job *entity.IngestionJob) error {
+	return r.updateClaimed(job, func(job *entity.IngestionJob) {
+		now := time.Now()
+		job.LockedAt = &now
+	})
+}
+
+// complete job
+func (r *jobRepository) Complete(ctx context.Context, job *entity.IngestionJob) error {
+	return r.updateClaimed(job, func(job *entity.IngestionJob) {
+		job.Status = entity.JobCompleted
+		job.LockedAt = nil
+	})
+}
+
+// retry job after delay
+func (r *jobRepository) Retry(ctx context.Context, job *entity.IngestionJob, delay time.Duration, lastError string) error {
+	return r.updateClaimed(job, func(job *entity.IngestionJob) {
+		job.Status = entity.JobPending
+		job.LastError = &lastError
+		job.RunAt = time.Now().Add(delay)
+		job.LockedAt = nil
+	})
+}
+
+// fail job permanently
+func (r *jobRepository) Fail(ctx context.Context, job *entity.IngestionJob, lastError string) error {
+	return r.updateClaimed(job, func(job *entity.IngestionJob) {
+		job.Status = entity.JobFailed
+		job.LastError = &lastError
+		job.LockedAt = nil
+	})
+}
+
+// recover jobs left RUNNING by a worker that died
+func (r *jobRepository) RecoverStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	recovered := 0
+	for id, job := range r.db.jobs {
+		if job.Status != entity.JobRunning || job.LockedAt == nil || !job.LockedAt.Before(now.Add(-staleAfter)) {
+			continue
+		}
+		job.Status = entity.JobPending
+		job.RunAt = now
+		job.LockedAt = nil
+		job.UpdatedAt = now
+		r.db.jobs[id] = job
+		recovered++
+	}
+	return recovered, nil
+}
+
+// updateClaimed applies apply to the job while it is RUNNING under the claim
+// that counted claimed.Attempts
+func (r *jobRepository) updateClaimed(claimed *entity.IngestionJob, apply func(job *entity.IngestionJob)) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	job, ok := r.db.jobs[claimed.ID]
+	if !ok || job.Status != entity.JobRunning || job.Attempts != claimed.Attempts {
+		return repository.ErrJobNotClaimed
+	}
+	apply(&job)
+	job.UpdatedAt = time.Now()
+	r.db.jobs[claimed.ID] = job
+	return nil
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type messageRepository struct {
+	db *DB
+}
+
+func NewMessageRepository(db *DB) repository.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// create message
+func (r *messageRepository) Create(ctx context.Context, msg *entity.Message) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.conversations[msg.ConversationID]; !ok {
+		return foreignKeyError("messages", "conversationId", msg.ConversationID)
+	}
+
+	r.insert(msg)
+	return nil
+}
+
+// CreateBatch creates multiple messages, none if a conversation is missing
+func (r *messageRepository) CreateBatch(ctx context.Context, msgs []entity.Message) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, msg := range msgs {
+		if _, ok := r.db.conversations[msg.ConversationID]; !ok {
+			return foreignKeyError("messages", "conversationId", msg.ConversationID)
+		}
+	}
+	for i := range msgs {
+		r.insert(&msgs[i])
+	}
+	return nil
+}
+
+// insert stores a new message after the last one, r.db.mu must be held
+func (r *messageRepository) insert(msg *entity.Message) {
+	msg.ID = uuid.New().String()
+	msg.CreatedAt = time.Now()
+
+	r.db.inserted(msg.ID)
+	msg.Sequence = r.db.seq
+	r.db.messages[msg.ID] = *msg
+}
+
+// list all messages of a conversation in chronological order
+func (r *messageRepository) ListByConversation(ctx context.Context, conversationID string) ([]entity.Message, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.byConversation(conversationID), nil
+}
+
+// list the last limit messages of a conversation in chronological order
+func (r *messageRepository) ListRecentByConversation(ctx context.Context, conversationID string, limit int) ([]entity.Message, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	messages := r.byConversation(conversationID)
+	if limit >= 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
+
+// byConversation returns the messages in sequence order, r.db.mu must be held
+func (r *messageRepository) byConversation(conversationID string) []entity.Message {
+	var messages []entity.Message
+	for _, msg := range r.db.messages {
+		if msg.ConversationID == conversationID {
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Sequence < messages[j].Sequence
+	})
+
+	return messages
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+type userRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) repository.UserRepository {
+	return &userRepository{db: db}
+}
+
+// create user
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.users {
+		if existing.Email == user.Email {
+			return uniqueError("users_email_key")
+		}
+	}
+
+	user.ID = uuid.New().String()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	r.db.users[user.ID] = *user
+	r.db.inserted(user.ID)
+	return nil
+}
+
+// find user by email, sql.ErrNoRows when missing like the postgres repository
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, user := range r.db.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// find user by id, sql.ErrNoRows when missing like the postgres repository
+func (r *userRepository) FindById(ctx context.Context, id string) (*entity.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user, ok := r.db.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &user, nil
+}
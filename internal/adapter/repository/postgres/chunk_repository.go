@@ -1,130 +1,343 @@
-package postgres
-
-import (
-	"context"
-	"time"
-
-	"rag-api/internal/domain/entity"
-	"rag-api/internal/domain/repository"
-
-	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
-	"github.com/pgvector/pgvector-go"
-)
-
-type chunkRepository struct {
-	db *sqlx.DB
-}
-
-func NewChunkRepository(db *sqlx.DB) repository.ChunkRepository {
-	return &chunkRepository{db: db}
-}
-
-// create chunk
-func (r *chunkRepository) Create(ctx context.Context, chunk *entity.DocumentChunk) error {
-	chunk.ID = uuid.New().String()
-	chunk.CreatedAt = time.Now()
-
-	query := `
-		INSERT INTO "document_chunks" ("id", "documentId", "chunkIndex", "content", "embedding", "metadata", "createdAt")
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-	_, err := r.db.ExecContext(ctx, query,
-		chunk.ID,
-		chunk.DocumentID,
-		chunk.ChunkIndex,
-		chunk.Content,
-		chunk.Embedding,
-		chunk.Metadata,
-		chunk.CreatedAt,
-	)
-	return err
-}
-
-// CreateBatch creates multiple chunks
-func (r *chunkRepository) CreateBatch(ctx context.Context, chunks []entity.DocumentChunk) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	query := `
-		INSERT INTO "document_chunks" ("id", "documentId", "chunkIndex", "content", "embedding", "metadata", "createdAt")
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-
-	for i := range chunks {
-		chunks[i].ID = uuid.New().String()
-		chunks[i].CreatedAt = time.Now()
-
-		_, err := tx.ExecContext(ctx, query,
-			chunks[i].ID,
-			chunks[i].DocumentID,
-			chunks[i].ChunkIndex,
-			chunks[i].Content,
-			chunks[i].Embedding,
-			chunks[i].Metadata,
-			chunks[i].CreatedAt,
-		)
-		if err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit()
-
-}
-
-// SearchSimilar searches for similar chunks using vector similarity
-func (r *chunkRepository) SearchSimilar(ctx context.Context, embedding pgvector.Vector, topK int, threshold float64) ([]entity.SimilarChunk, error) {
-	query := `
-		SELECT 
-			dc."id",
-			dc."documentId",
-			dc."chunkIndex",
-			dc."content",
-			dc."metadata",
-			dc."createdAt",		
-		1 - (dc."embedding" <=> $1) AS similarity
-		FROM "document_chunks" dc
-		INNER JOIN "documents" d ON dc."documentId" = d."id"
-		WHERE d."status" = 'COMPLETED'
-		AND (1 - (dc."embedding" <=> $1)) >= $2
-		ORDER BY dc."embedding" <=> $1
-		LIMIT $3
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, embedding, threshold, topK)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var chunks []entity.SimilarChunk
-	for rows.Next() {
-		var chunk entity.SimilarChunk
-		err := rows.Scan(
-			&chunk.ID,
-			&chunk.DocumentID,
-			&chunk.ChunkIndex,
-			&chunk.Content,
-			&chunk.Metadata,
-			&chunk.CreatedAt,
-			&chunk.Similarity,
-		)
-		if err != nil {
-			return nil, err
-		}
-		chunks = append(chunks, chunk)
-	}
-
-	return chunks, nil
-}
-
-// DeleteByDocumentID deletes all chunks containing the document ID
-func (r *chunkRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
-	query := `DELETE FROM "document_chunks" WHERE "documentId" = $1`
-	_, err := r.db.ExecContext(ctx, query, documentID)
-	return err
-}
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pgvector/pgvector-go"
+)
+
+type chunkRepository struct {
+	db *sqlx.DB
+}
+
+func NewChunkRepository(db *sqlx.DB) repository.ChunkRepository {
+	return &chunkRepository{db: db}
+}
+
+// create chunk
+func (r *chunkRepository) Create(ctx context.Context, chunk *entity.DocumentChunk) error {
+	chunk.ID = uuid.New().String()
+	chunk.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO "document_chunks" ("id", "documentId", "chunkIndex", "content", "embedding", "metadata", "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		chunk.ID,
+		chunk.DocumentID,
+		chunk.ChunkIndex,
+		chunk.Content,
+		chunk.Embedding,
+		chunk.Metadata,
+		chunk.CreatedAt,
+	)
+	return err
+}
+
+// CreateBatch creates multiple chunks
+func (r *chunkRepository) CreateBatch(ctx context.Context, chunks []entity.DocumentChunk) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertChunks(ctx, tx, chunks); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+
+}
+
+// ReplaceByDocumentID swaps all chunks of a document in one transaction, so
+// searches see either the old chunks or the new ones, never a mix
+func (r *chunkRepository) ReplaceByDocumentID(ctx context.Context, documentID string, chunks []entity.DocumentChunk) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM "document_chunks" WHERE "documentId" = $1`
+	if _, err := tx.ExecContext(ctx, query, documentID); err != nil {
+		return err
+	}
+
+	if err := insertChunks(ctx, tx, chunks); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+
+}
+
+func insertChunks(ctx context.Context, tx *sqlx.Tx, chunks []entity.DocumentChunk) error {
+	query := `
+		INSERT INTO "document_chunks" ("id", "documentId", "chunkIndex", "content", "embedding", "metadata", "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for i := range chunks {
+		chunks[i].ID = uuid.New().String()
+		chunks[i].CreatedAt = time.Now()
+
+		_, err := tx.ExecContext(ctx, query,
+			chunks[i].ID,
+			chunks[i].DocumentID,
+			chunks[i].ChunkIndex,
+			chunks[i].Content,
+			chunks[i].Embedding,
+			chunks[i].Metadata,
+			chunks[i].CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchSimilar searches for similar chunks using vector similarity,
+// limited to documents owned by filter.UserID or marked PUBLIC. Embeddings are
+// returned too, to compare the chunks with each other.
+func (r *chunkRepository) SearchSimilar(ctx context.Context, embedding pgvector.Vector, filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+	args := []interface{}{embedding, filter.Threshold, filter.UserID}
+	args, conditions := filterConditions(args, filter)
+
+	args = append(args, filter.TopK)
+	query := fmt.Sprintf(`
+		SELECT 
+			dc."id",
+			dc."documentId",
+			dc."chunkIndex",
+			dc."content",
+			dc."embedding",
+			dc."metadata",
+			dc."createdAt",		
+		1 - (dc."embedding" <=> $1) AS similarity
+		FROM "document_chunks" dc
+		INNER JOIN "documents" d ON dc."documentId" = d."id"
+		WHERE d."status" = 'COMPLETED'
+		AND (d."userId" = $3 OR d."visibility" = 'PUBLIC')
+		AND (1 - (dc."embedding" <=> $1)) >= $2
+		%s
+		ORDER BY dc."embedding" <=> $1
+		LIMIT $%d
+	`, conditions, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []entity.SimilarChunk
+	for rows.Next() {
+		var chunk entity.SimilarChunk
+		err := rows.Scan(
+			&chunk.ID,
+			&chunk.DocumentID,
+			&chunk.ChunkIndex,
+			&chunk.Content,
+			&chunk.Embedding,
+			&chunk.Metadata,
+			&chunk.CreatedAt,
+			&chunk.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// SearchHybrid fuses the vector ranking with a full-text ranking of the
+// "contentTsv" column. Any word of the query matches, ranked with
+// ts_rank_cd normalized by the chunk length.
+func (r *chunkRepository) SearchHybrid(ctx context.Context, embedding pgvector.Vector, search repository.HybridSearch, filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+	args := []interface{}{embedding, filter.Threshold, filter.UserID, search.Query,
+		search.VectorWeight, search.KeywordWeight, search.RRFK, search.Candidates}
+	args, conditions := filterConditions(args, filter)
+
+	args = append(args, filter.TopK)
+	query := fmt.Sprintf(`
+		WITH "vectorRank" AS (
+			SELECT dc."id", ROW_NUMBER() OVER (ORDER BY dc."embedding" <=> $1) AS "rank"
+			FROM "document_chunks" dc
+			INNER JOIN "documents" d ON dc."documentId" = d."id"
+			WHERE d."status" = 'COMPLETED'
+			AND (d."userId" = $3 OR d."visibility" = 'PUBLIC')
+			AND (1 - (dc."embedding" <=> $1)) >= $2
+			%[1]s
+			ORDER BY dc."embedding" <=> $1
+			LIMIT $8
+		),
+		"keywordQuery" AS (
+			SELECT replace(plainto_tsquery('simple', $4)::text, ' & ', ' | ')::tsquery AS "query"
+		),
+		"keywordRank" AS (
+			SELECT dc."id", ROW_NUMBER() OVER (ORDER BY ts_rank_cd(dc."contentTsv", q."query", 1) DESC) AS "rank"
+			FROM "document_chunks" dc
+			INNER JOIN "documents" d ON dc."documentId" = d."id"
+			CROSS JOIN "keywordQuery" q
+			WHERE d."status" = 'COMPLETED'
+			AND (d."userId" = $3 OR d."visibility" = 'PUBLIC')
+			AND dc."contentTsv" @@ q."query"
+			AND dc."embedding" IS NOT NULL
+			%[1]s
+			ORDER BY ts_rank_cd(dc."contentTsv", q."query", 1) DESC
+			LIMIT $8
+		)
+		SELECT
+			dc."id",
+			dc."documentId",
+			dc."chunkIndex",
+			dc."content",
+			dc."embedding",
+			dc."metadata",
+			dc."createdAt",
+			COALESCE(1 - (dc."embedding" <=> $1), 0) AS similarity,
+			COALESCE($5::float8 / ($7::int + v."rank"), 0) +
+				COALESCE($6::float8 / ($7::int + k."rank"), 0) AS score
+		FROM "vectorRank" v
+		FULL OUTER JOIN "keywordRank" k ON k."id" = v."id"
+		INNER JOIN "document_chunks" dc ON dc."id" = COALESCE(v."id", k."id")
+		ORDER BY score DESC, similarity DESC
+		LIMIT $%[2]d
+	`, conditions, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []entity.SimilarChunk
+	for rows.Next() {
+		var chunk entity.SimilarChunk
+		err := rows.Scan(
+			&chunk.ID,
+			&chunk.DocumentID,
+			&chunk.ChunkIndex,
+			&chunk.Content,
+			&chunk.Embedding,
+			&chunk.Metadata,
+			&chunk.CreatedAt,
+			&chunk.Similarity,
+			&chunk.Score,
+		)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// ListByRanges looks the ranges up through the (documentId, chunkIndex) index
+func (r *chunkRepository) ListByRanges(ctx context.Context, userID string, ranges []repository.ChunkRange) ([]entity.DocumentChunk, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	documentIDs := make([]string, len(ranges))
+	froms := make([]int, len(ranges))
+	tos := make([]int, len(ranges))
+	for i, chunkRange := range ranges {
+		documentIDs[i] = chunkRange.DocumentID
+		froms[i] = chunkRange.From
+		tos[i] = chunkRange.To
+	}
+
+	query := `
+		SELECT DISTINCT
+			dc."id",
+			dc."documentId",
+			dc."chunkIndex",
+			dc."content",
+			dc."metadata",
+			dc."createdAt"
+		FROM "document_chunks" dc
+		INNER JOIN unnest($1::text[], $2::int[], $3::int[]) AS r("documentId", "from", "to")
+			ON dc."documentId" = r."documentId"
+			AND dc."chunkIndex" BETWEEN r."from" AND r."to"
+		INNER JOIN "documents" d ON dc."documentId" = d."id"
+		WHERE d."status" = 'COMPLETED'
+		AND (d."userId" = $4 OR d."visibility" = 'PUBLIC')
+		ORDER BY dc."documentId", dc."chunkIndex"
+	`
+	rows, err := r.db.QueryContext(ctx, query, documentIDs, froms, tos, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []entity.DocumentChunk
+	for rows.Next() {
+		var chunk entity.DocumentChunk
+		err := rows.Scan(
+			&chunk.ID,
+			&chunk.DocumentID,
+			&chunk.ChunkIndex,
+			&chunk.Content,
+			&chunk.Metadata,
+			&chunk.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// filterConditions appends the optional filters of a search to args and
+// returns them as conditions on documents d
+func filterConditions(args []interface{}, filter repository.ChunkSearchFilter) ([]interface{}, string) {
+	var conditions []string
+
+	if len(filter.DocumentIDs) > 0 {
+		args = append(args, filter.DocumentIDs)
+		conditions = append(conditions, fmt.Sprintf(`AND d."id" = ANY($%d)`, len(args)))
+	}
+	if len(filter.MimeTypes) > 0 {
+		args = append(args, filter.MimeTypes)
+		conditions = append(conditions, fmt.Sprintf(`AND d."mimeType" = ANY($%d)`, len(args)))
+	}
+	if filter.UploadedBy != "" {
+		args = append(args, filter.UploadedBy)
+		conditions = append(conditions, fmt.Sprintf(`AND d."userId" = $%d`, len(args)))
+	}
+	if filter.CreatedFrom != nil {
+		args = append(args, *filter.CreatedFrom)
+		conditions = append(conditions, fmt.Sprintf(`AND d."createdAt" >= $%d`, len(args)))
+	}
+	if filter.CreatedTo != nil {
+		args = append(args, *filter.CreatedTo)
+		conditions = append(conditions, fmt.Sprintf(`AND d."createdAt" <= $%d`, len(args)))
+	}
+
+	return args, strings.Join(conditions, "\n\t\t")
+}
+
+// DeleteByDocumentID deletes all chunks containing the document ID
+func (r *chunkRepository) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	query := `DELETE FROM "document_chunks" WHERE "documentId" = $1`
+	_, err := r.db.ExecContext(ctx, query, documentID)
+	return err
+}
@@ -0,0 +1,22 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"rag-api/internal/adapter/repository/postgres"
+	"rag-api/internal/adapter/repository/repositorytest"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		db := newDB(t)
+		return repositorytest.Repositories{
+			Users:         postgres.NewUserRepository(db),
+			Documents:     postgres.NewDocumentRepository(db),
+			Chunks:        postgres.NewChunkRepository(db),
+			Jobs:          postgres.NewJobRepository(db),
+			Conversations: postgres.NewConversationRepository(db),
+			Messages:      postgres.NewMessageRepository(db),
+		}
+	})
+}
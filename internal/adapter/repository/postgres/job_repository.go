@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type jobRepository struct {
+	db *sqlx.DB
+}
+
+func NewJobRepository(db *sqlx.DB) repository.JobRepository {
+	return &jobRepository{db: db}
+}
+
+// enqueue job, due immediately
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.IngestionJob) error {
+	_, err := r.insert(ctx, job, "")
+	return err
+}
+
+// EnqueueIfIdle relies on the partial unique index of active jobs, so two
+// concurrent requests can't both queue the same document
+func (r *jobRepository) EnqueueIfIdle(ctx context.Context, job *entity.IngestionJob) (bool, error) {
+	return r.insert(ctx, job, `ON CONFLICT ("documentId") WHERE "status" IN ('PENDING', 'RUNNING') DO NOTHING`)
+}
+
+func (r *jobRepository) insert(ctx context.Context, job *entity.IngestionJob, onConflict string) (bool, error) {
+	job.ID = uuid.New().String()
+	job.Status = entity.JobPending
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+	job.RunAt = job.CreatedAt
+
+	query := `
+		INSERT INTO "ingestion_jobs" ("id", "documentId", "status", "attempts", "mimeType", "runAt", "createdAt", "updatedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	` + onConflict
+	result, err := r.db.ExecContext(ctx, query, job.ID, job.DocumentID, job.Status, job.Attempts, job.MimeType, job.RunAt, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	return inserted > 0, err
+}
+
+// Claim uses SKIP LOCKED so concurrent workers, in this process or another
+// one, never pick up the same job
+func (r *jobRepository) Claim(ctx context.Context) (*entity.IngestionJob, error) {
+	var job entity.IngestionJob
+	query := `
+		UPDATE "ingestion_jobs"
+		SET "status" = 'RUNNING', "attempts" = "attempts" + 1, "lockedAt" = NOW(), "updatedAt" = NOW()
+		WHERE "id" = (
+			SELECT "id" FROM "ingestion_jobs"
+			WHERE "status" = 'PENDING' AND "runAt" <= NOW()
+			ORDER BY "runAt"
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &job, query)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// refresh the lock of a running job
+func (r *jobRepository) Heartbeat(ctx context.Context, job *entity.IngestionJob) error {
+	return r.updateClaimed(ctx, job, `"lockedAt" = NOW()`)
+}
+
+// complete job
+func (r *jobRepository) Complete(ctx context.Context, job *entity.IngestionJob) error {
+	return r.updateClaimed(ctx, job, `"status" = 'COMPLETED', "lockedAt" = NULL`)
+}
+
+// retry job after delay
+func (r *jobRepository) Retry(ctx context.Context, job *entity.IngestionJob, delay time.Duration, lastError string) error {
+	return r.updateClaimed(ctx, job, `"status" = 'PENDING', "lastError" = $3, "runAt" = NOW() + $4 * INTERVAL '1 millisecond', "lockedAt" = NULL`, lastError, delay.Milliseconds())
+}
+
+// fail job permanently
+func (r *jobRepository) Fail(ctx context.Context, job *entity.IngestionJob, lastError string) error {
+	return r.updateClaimed(ctx, job, `"status" = 'FAILED', "lastError" = $3, "lockedAt" = NULL`, lastError)
+}
+
+// updateClaimed applies set to the job while it is RUNNING under the claim
+// that counted job.Attempts. Further arguments start at $3.
+func (r *jobRepository) updateClaimed(ctx context.Context, job *entity.IngestionJob, set string, args ...any) error {
+	query := `
+		UPDATE "ingestion_jobs"
+		SET ` + set + `, "updatedAt" = NOW()
+		WHERE "id" = $1 AND "status" = 'RUNNING' AND "attempts" = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, append([]any{job.ID, job.Attempts}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return repository.ErrJobNotClaimed
+	}
+	return nil
+}
+
+// recover jobs left RUNNING by a worker that died
+func (r *jobRepository) RecoverStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	query := `
+		UPDATE "ingestion_jobs"
+		SET "status" = 'PENDING', "runAt" = NOW(), "lockedAt" = NULL, "updatedAt" = NOW()
+		WHERE "status" = 'RUNNING' AND "lockedAt" < NOW() - $1 * INTERVAL '1 millisecond'
+	`
+	result, err := r.db.ExecContext(ctx, query, staleAfter.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	recovered, err := result.RowsAffected()
+	return int(recovered), err
+}
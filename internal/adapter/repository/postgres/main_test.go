@@ -0,0 +1,121 @@
+package postgres_test
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"rag-api/pkg/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// testDB is a database created for this run, nil without DATABASE_URL
+var testDB *sqlx.DB
+
+// TestMain runs the migrations in a fresh database next to the one of
+// DATABASE_URL, since they drop and recreate every table
+func TestMain(m *testing.M) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		os.Exit(m.Run())
+	}
+
+	admin, err := database.Connect(databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect to DATABASE_URL: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := fmt.Sprintf("rag_test_%d", rand.Int63())
+	if _, err := admin.Exec(`CREATE DATABASE "` + name + `"`); err != nil {
+		fmt.Fprintf(os.Stderr, "create test database: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := run(m, withDatabase(databaseURL, name))
+
+	if _, err := admin.Exec(`DROP DATABASE "` + name + `" WITH (FORCE)`); err != nil {
+		fmt.Fprintf(os.Stderr, "drop test database: %v\n", err)
+	}
+	admin.Close()
+	os.Exit(code)
+}
+
+func run(m *testing.M, databaseURL string) int {
+	db, err := database.Connect(databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect to test database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := migrate(db); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate test database: %v\n", err)
+		return 1
+	}
+
+	testDB = db
+	return m.Run()
+}
+
+// withDatabase points a URL or keyword/value connection string at name
+func withDatabase(databaseURL, name string) string {
+	u, err := url.Parse(databaseURL)
+	if err != nil || u.Scheme == "" {
+		return databaseURL + " dbname=" + name
+	}
+	u.Path = "/" + name
+	return u.String()
+}
+
+func migrate(db *sqlx.DB) error {
+	files, err := filepath.Glob("../../../../migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migrations found")
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		// no arguments, so the whole file runs as one simple query
+		if _, err := db.Exec(string(migration)); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+// newDB empties every table of the test database, or skips without one
+func newDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	if testDB == nil {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	var tables []string
+	err := testDB.Select(&tables, `SELECT tablename FROM pg_tables WHERE schemaname = 'public'`)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	for i, table := range tables {
+		tables[i] = `"` + table + `"`
+	}
+	if len(tables) > 0 {
+		if _, err := testDB.Exec(`TRUNCATE ` + strings.Join(tables, ", ") + ` CASCADE`); err != nil {
+			t.Fatalf("truncate tables: %v", err)
+		}
+	}
+	return testDB
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// insertMessageQuery returns the sequence the database assigned
+const insertMessageQuery = `
+	INSERT INTO "messages" ("id", "conversationId", "role", "content", "sources", "createdAt")
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING "sequence"
+`
+
+type messageRepository struct {
+	db *sqlx.DB
+}
+
+func NewMessageRepository(db *sqlx.DB) repository.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// create message
+func (r *messageRepository) Create(ctx context.Context, msg *entity.Message) error {
+	msg.ID = uuid.New().String()
+	msg.CreatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, insertMessageQuery, msg.ID, msg.ConversationID, msg.Role, msg.Content, msg.Sources, msg.CreatedAt).Scan(&msg.Sequence)
+}
+
+// CreateBatch creates multiple messages in one transaction
+func (r *messageRepository) CreateBatch(ctx context.Context, msgs []entity.Message) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range msgs {
+		msgs[i].ID = uuid.New().String()
+		msgs[i].CreatedAt = time.Now()
+
+		err := tx.QueryRowContext(ctx, insertMessageQuery,
+			msgs[i].ID,
+			msgs[i].ConversationID,
+			msgs[i].Role,
+			msgs[i].Content,
+			msgs[i].Sources,
+			msgs[i].CreatedAt,
+		).Scan(&msgs[i].Sequence)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// list all messages of a conversation in chronological order
+func (r *messageRepository) ListByConversation(ctx context.Context, conversationID string) ([]entity.Message, error) {
+	var messages []entity.Message
+	query := `SELECT * FROM "messages" WHERE "conversationId" = $1 ORDER BY "sequence" ASC`
+	err := r.db.SelectContext(ctx, &messages, query, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// list the last limit messages of a conversation in chronological order
+func (r *messageRepository) ListRecentByConversation(ctx context.Context, conversationID string, limit int) ([]entity.Message, error) {
+	var messages []entity.Message
+	query := `
+		SELECT * FROM (
+			SELECT * FROM "messages" WHERE "conversationId" = $1 ORDER BY "sequence" DESC LIMIT $2
+		) recent
+		ORDER BY "sequence" ASC
+	`
+	err := r.db.SelectContext(ctx, &messages, query, conversationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
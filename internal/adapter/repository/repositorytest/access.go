@@ -0,0 +1,102 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+// testSearchAccess checks that both searches return the user's own private
+// chunks and public chunks, but never another student's private chunks
+func testSearchAccess(t *testing.T, r Repositories) {
+	owner := createUser(t, r, "owner@kampus.ac.id")
+	other := createUser(t, r, "other@kampus.ac.id")
+
+	ownPrivate := createDocument(t, r, owner.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	otherPublic := createDocument(t, r, other.ID, entity.StatusCompleted, entity.VisibilityPublic)
+	otherPrivate := createDocument(t, r, other.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+
+	// the other student's private chunk is the best match of both rankings
+	ownChunk := createChunks(t, r, ownPrivate.ID, vector(0.8, 0.6))[0]
+	publicChunk := createChunks(t, r, otherPublic.ID, vector(0.6, 0.8))[0]
+	createChunks(t, r, otherPrivate.ID, vector(1))
+
+	searches := []struct {
+		name   string
+		search func(filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error)
+	}{
+		{"similar", func(filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+			return r.Chunks.SearchSimilar(context.Background(), vector(1), filter)
+		}},
+		{"hybrid", func(filter repository.ChunkSearchFilter) ([]entity.SimilarChunk, error) {
+			return r.Chunks.SearchHybrid(context.Background(), vector(1), hybridSearch("chunk"), filter)
+		}},
+	}
+
+	for _, search := range searches {
+		t.Run(search.name, func(t *testing.T) {
+			found, err := search.search(repository.ChunkSearchFilter{UserID: owner.ID, TopK: 5})
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if got := chunkIDs(found); !equalStrings(got, ownChunk.ID, publicChunk.ID) {
+				t.Errorf("got chunks %v, want the own private %s and the public %s", got, ownChunk.ID, publicChunk.ID)
+			}
+
+			// filtering on the private document must not bypass the access check
+			found, err = search.search(repository.ChunkSearchFilter{UserID: owner.ID, TopK: 5, DocumentIDs: []string{otherPrivate.ID}})
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if len(found) != 0 {
+				t.Errorf("got chunks %v of another student's private document", chunkIDs(found))
+			}
+		})
+	}
+}
+
+// testListByRangesAccess checks that neighbouring chunks are fetched with the
+// same access check as the search
+func testListByRangesAccess(t *testing.T, r Repositories) {
+	owner := createUser(t, r, "owner@kampus.ac.id")
+	other := createUser(t, r, "other@kampus.ac.id")
+
+	ownPrivate := createDocument(t, r, owner.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	ownProcessing := createDocument(t, r, owner.ID, entity.StatusProcessing, entity.VisibilityPrivate)
+	otherPublic := createDocument(t, r, other.ID, entity.StatusCompleted, entity.VisibilityPublic)
+	otherPrivate := createDocument(t, r, other.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	for _, doc := range []*entity.Document{ownPrivate, ownProcessing, otherPublic, otherPrivate} {
+		createChunks(t, r, doc.ID, vector(1), vector(1))
+	}
+
+	listed, err := r.Chunks.ListByRanges(context.Background(), owner.ID, []repository.ChunkRange{
+		{DocumentID: ownPrivate.ID, From: 0, To: 1},
+		{DocumentID: ownProcessing.ID, From: 0, To: 1},
+		{DocumentID: otherPublic.ID, From: 0, To: 1},
+		{DocumentID: otherPrivate.ID, From: 0, To: 1},
+	})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, chunk := range listed {
+		counts[chunk.DocumentID]++
+	}
+	if len(listed) != 4 || counts[ownPrivate.ID] != 2 || counts[otherPublic.ID] != 2 {
+		t.Errorf("got chunks per document %v, want 2 of the own private %s and 2 of the public %s", counts, ownPrivate.ID, otherPublic.ID)
+	}
+}
+
+// hybridSearch weighs both rankings equally, like the default config
+func hybridSearch(query string) repository.HybridSearch {
+	return repository.HybridSearch{
+		Query:         query,
+		VectorWeight:  1,
+		KeywordWeight: 1,
+		RRFK:          60,
+		Candidates:    20,
+	}
+}
@@ -0,0 +1,200 @@
+package repositorytest
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+func testChunkConstraints(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+
+	chunks := createChunks(t, r, doc.ID, vector(1), vector(0, 1))
+	for _, chunk := range chunks {
+		if chunk.ID == "" {
+			t.Fatal("CreateBatch did not assign an ID")
+		}
+	}
+
+	// the taken index comes last, so nothing may be left of the batch
+	batch := []entity.DocumentChunk{
+		{DocumentID: doc.ID, ChunkIndex: 2, Content: "chunk 2", Embedding: vector(1), Metadata: []byte(`{}`)},
+		{DocumentID: doc.ID, ChunkIndex: 1, Content: "chunk 1", Embedding: vector(1), Metadata: []byte(`{}`)},
+	}
+	if err := r.Chunks.CreateBatch(ctx, batch); err == nil {
+		t.Error("CreateBatch with a taken chunk index succeeded")
+	}
+	listed, err := r.Chunks.ListByRanges(ctx, user.ID, []repository.ChunkRange{{DocumentID: doc.ID, From: 0, To: 10}})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("got %d chunks after the failed batch, want the 2 created before", len(listed))
+	}
+
+	orphan := []entity.DocumentChunk{
+		{DocumentID: "00000000-0000-0000-0000-000000000000", Content: "orphan", Embedding: vector(1), Metadata: []byte(`{}`)},
+	}
+	if err := r.Chunks.CreateBatch(ctx, orphan); err == nil {
+		t.Error("CreateBatch for a missing document succeeded")
+	}
+}
+
+func testSearchSimilar(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	chunks := createChunks(t, r, doc.ID, vector(0, 1), vector(1), vector(0.8, 0.6))
+
+	filter := repository.ChunkSearchFilter{UserID: user.ID, TopK: 5, Threshold: 0.5}
+	found, err := r.Chunks.SearchSimilar(ctx, vector(1), filter)
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if got := chunkIDs(found); !equalStrings(got, chunks[1].ID, chunks[2].ID) {
+		t.Fatalf("got chunks %v, want %v most similar first above the threshold", got, []string{chunks[1].ID, chunks[2].ID})
+	}
+	if math.Abs(found[0].Similarity-1) > 1e-6 || math.Abs(found[1].Similarity-0.8) > 1e-6 {
+		t.Errorf("got similarities %v and %v, want 1 and 0.8", found[0].Similarity, found[1].Similarity)
+	}
+	if len(found[0].Embedding.Slice()) != Dimensions {
+		t.Errorf("got an embedding of %d dimensions, want %d", len(found[0].Embedding.Slice()), Dimensions)
+	}
+	if found[0].DocumentID != doc.ID || found[0].Content != "chunk 1" || found[0].ChunkIndex != 1 {
+		t.Errorf("got chunk %+v, want chunk 1 of document %s", found[0].DocumentChunk, doc.ID)
+	}
+
+	filter.TopK = 1
+	found, err = r.Chunks.SearchSimilar(ctx, vector(1), filter)
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if got := chunkIDs(found); !equalStrings(got, chunks[1].ID) {
+		t.Errorf("got chunks %v with TopK 1, want %v", got, []string{chunks[1].ID})
+	}
+
+	// documents still being processed are never searched
+	processing := createDocument(t, r, user.ID, entity.StatusProcessing, entity.VisibilityPrivate)
+	createChunks(t, r, processing.ID, vector(1))
+	filter.TopK = 5
+	found, err = r.Chunks.SearchSimilar(ctx, vector(1), filter)
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if got := chunkIDs(found); !equalStrings(got, chunks[1].ID, chunks[2].ID) {
+		t.Errorf("got chunks %v, want only the completed document's %v", got, []string{chunks[1].ID, chunks[2].ID})
+	}
+}
+
+func testSearchFilters(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	other := createUser(t, r, "siti@kampus.ac.id")
+
+	text := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	public := createDocument(t, r, other.ID, entity.StatusCompleted, entity.VisibilityPublic)
+	textChunk := createChunks(t, r, text.ID, vector(1))[0]
+	publicChunk := createChunks(t, r, public.ID, vector(0.8, 0.6))[0]
+
+	tests := []struct {
+		name   string
+		filter repository.ChunkSearchFilter
+		want   []string
+	}{
+		{"none", repository.ChunkSearchFilter{}, []string{textChunk.ID, publicChunk.ID}},
+		{"document", repository.ChunkSearchFilter{DocumentIDs: []string{public.ID}}, []string{publicChunk.ID}},
+		{"uploader", repository.ChunkSearchFilter{UploadedBy: user.ID}, []string{textChunk.ID}},
+		{"mime type", repository.ChunkSearchFilter{MimeTypes: []string{"application/pdf"}}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := test.filter
+			filter.UserID = user.ID
+			filter.TopK = 5
+
+			found, err := r.Chunks.SearchSimilar(ctx, vector(1), filter)
+			if err != nil {
+				t.Fatalf("SearchSimilar: %v", err)
+			}
+			if got := chunkIDs(found); !equalStrings(got, test.want...) {
+				t.Errorf("got chunks %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func testReplaceAndDeleteChunks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	kept := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	createChunks(t, r, doc.ID, vector(1), vector(1), vector(1))
+	createChunks(t, r, kept.ID, vector(1))
+
+	replacement := []entity.DocumentChunk{
+		{DocumentID: doc.ID, ChunkIndex: 0, Content: "replacement", Embedding: vector(1), Metadata: []byte(`{}`)},
+	}
+	if err := r.Chunks.ReplaceByDocumentID(ctx, doc.ID, replacement); err != nil {
+		t.Fatalf("ReplaceByDocumentID: %v", err)
+	}
+	listed, err := r.Chunks.ListByRanges(ctx, user.ID, []repository.ChunkRange{{DocumentID: doc.ID, From: 0, To: 10}})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Content != "replacement" {
+		t.Errorf("got %+v after ReplaceByDocumentID, want only the replacement", listed)
+	}
+
+	if err := r.Chunks.DeleteByDocumentID(ctx, doc.ID); err != nil {
+		t.Fatalf("DeleteByDocumentID: %v", err)
+	}
+	listed, err = r.Chunks.ListByRanges(ctx, user.ID, []repository.ChunkRange{
+		{DocumentID: doc.ID, From: 0, To: 10},
+		{DocumentID: kept.ID, From: 0, To: 10},
+	})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+	if len(listed) != 1 || listed[0].DocumentID != kept.ID {
+		t.Errorf("got %+v after DeleteByDocumentID, want only the other document's chunk", listed)
+	}
+}
+
+func testListByRanges(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+	createChunks(t, r, doc.ID, vector(1), vector(1), vector(1), vector(1), vector(1), vector(1))
+
+	// overlapping ranges return every chunk once, in order
+	listed, err := r.Chunks.ListByRanges(ctx, user.ID, []repository.ChunkRange{
+		{DocumentID: doc.ID, From: 3, To: 4},
+		{DocumentID: doc.ID, From: 0, To: 1},
+		{DocumentID: doc.ID, From: 1, To: 3},
+	})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+	var indexes []int
+	for _, chunk := range listed {
+		indexes = append(indexes, chunk.ChunkIndex)
+	}
+	if len(indexes) != 5 {
+		t.Fatalf("got chunk indexes %v, want 0 to 4", indexes)
+	}
+	for i, index := range indexes {
+		if index != i {
+			t.Fatalf("got chunk indexes %v, want 0 to 4", indexes)
+		}
+	}
+
+	listed, err = r.Chunks.ListByRanges(ctx, user.ID, nil)
+	if err != nil || len(listed) != 0 {
+		t.Errorf("ListByRanges without ranges = %d chunks, %v, want none", len(listed), err)
+	}
+}
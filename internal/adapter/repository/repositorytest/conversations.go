@@ -0,0 +1,154 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rag-api/internal/domain/entity"
+)
+
+func createConversation(t *testing.T, r Repositories, userID, title string) *entity.Conversation {
+	t.Helper()
+	conv := &entity.Conversation{UserID: userID, Title: title}
+	if err := r.Conversations.Create(context.Background(), conv); err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	// keep updatedAt of later conversations apart at millisecond precision
+	time.Sleep(5 * time.Millisecond)
+	return conv
+}
+
+func conversationTitles(convs []entity.Conversation) []string {
+	titles := make([]string, len(convs))
+	for i, conv := range convs {
+		titles[i] = conv.Title
+	}
+	return titles
+}
+
+func messageContents(messages []entity.Message) []string {
+	contents := make([]string, len(messages))
+	for i, msg := range messages {
+		contents[i] = msg.Content
+	}
+	return contents
+}
+
+func testConversations(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, r, "owner@kampus.ac.id")
+	other := createUser(t, r, "other@kampus.ac.id")
+
+	first := createConversation(t, r, owner.ID, "Graf")
+	createConversation(t, r, owner.ID, "Pohon")
+	createConversation(t, r, other.ID, "Rahasia")
+
+	// most recently active first, paginated
+	convs, total, err := r.Conversations.List(ctx, owner.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := conversationTitles(convs); total != 2 || !equalStrings(got, "Pohon", "Graf") {
+		t.Errorf("List = %v of %d, want [Pohon Graf] of 2", got, total)
+	}
+	convs, total, err = r.Conversations.List(ctx, owner.ID, 2, 1)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := conversationTitles(convs); total != 2 || !equalStrings(got, "Graf") {
+		t.Errorf("List of page 2 = %v of %d, want [Graf] of 2", got, total)
+	}
+
+	// renaming bumps the conversation to the top
+	first.Title = "Graf berarah"
+	if err := r.Conversations.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	convs, _, err = r.Conversations.List(ctx, owner.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := conversationTitles(convs); !equalStrings(got, "Graf berarah", "Pohon") {
+		t.Errorf("List after Update = %v, want [Graf berarah Pohon]", got)
+	}
+
+	// another user can't see the conversation
+	found, err := r.Conversations.FindByIDAndUserID(ctx, first.ID, other.ID)
+	if err != nil || found != nil {
+		t.Errorf("FindByIDAndUserID of another user = %+v, %v, want nil, nil", found, err)
+	}
+	found, err = r.Conversations.FindByIDAndUserID(ctx, first.ID, owner.ID)
+	if err != nil || found == nil || found.Title != "Graf berarah" {
+		t.Fatalf("FindByIDAndUserID = %+v, %v, want the renamed conversation", found, err)
+	}
+
+	// deleting removes the messages with the conversation
+	if err := r.Messages.Create(ctx, &entity.Message{ConversationID: first.ID, Role: entity.MessageRoleUser, Content: "Apa itu graf?"}); err != nil {
+		t.Fatalf("Create message: %v", err)
+	}
+	if err := r.Conversations.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	found, err = r.Conversations.FindByIDAndUserID(ctx, first.ID, owner.ID)
+	if err != nil || found != nil {
+		t.Errorf("FindByIDAndUserID after Delete = %+v, %v, want nil, nil", found, err)
+	}
+	messages, err := r.Messages.ListByConversation(ctx, first.ID)
+	if err != nil || len(messages) != 0 {
+		t.Errorf("messages after Delete = %d, %v, want them deleted with the conversation", len(messages), err)
+	}
+}
+
+func testMessages(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	conv := createConversation(t, r, user.ID, "Graf")
+	other := createConversation(t, r, user.ID, "Pohon")
+
+	// turns written within the same millisecond keep their order
+	for _, question := range []string{"q1", "q2", "q3"} {
+		turn := []entity.Message{
+			{ConversationID: conv.ID, Role: entity.MessageRoleUser, Content: question},
+			{ConversationID: conv.ID, Role: entity.MessageRoleAssistant, Content: "a" + question[1:], Sources: []byte(`[]`)},
+		}
+		if err := r.Messages.CreateBatch(ctx, turn); err != nil {
+			t.Fatalf("CreateBatch: %v", err)
+		}
+		if turn[0].ID == "" || turn[0].Sequence >= turn[1].Sequence {
+			t.Fatalf("CreateBatch assigned %+v, want IDs and increasing sequences", turn)
+		}
+	}
+	if err := r.Messages.Create(ctx, &entity.Message{ConversationID: other.ID, Role: entity.MessageRoleUser, Content: "lain"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	messages, err := r.Messages.ListByConversation(ctx, conv.ID)
+	if err != nil {
+		t.Fatalf("ListByConversation: %v", err)
+	}
+	if got := messageContents(messages); !equalStrings(got, "q1", "a1", "q2", "a2", "q3", "a3") {
+		t.Errorf("ListByConversation = %v, want the turns in order", got)
+	}
+
+	recent, err := r.Messages.ListRecentByConversation(ctx, conv.ID, 3)
+	if err != nil {
+		t.Fatalf("ListRecentByConversation: %v", err)
+	}
+	if got := messageContents(recent); !equalStrings(got, "a2", "q3", "a3") {
+		t.Errorf("ListRecentByConversation = %v, want the last 3 in order", got)
+	}
+
+	// a batch with a missing conversation stores nothing
+	batch := []entity.Message{
+		{ConversationID: conv.ID, Role: entity.MessageRoleUser, Content: "q4"},
+		{ConversationID: "missing", Role: entity.MessageRoleAssistant, Content: "a4"},
+	}
+	if err := r.Messages.CreateBatch(ctx, batch); err == nil {
+		t.Error("CreateBatch with a missing conversation succeeded")
+	}
+	messages, err = r.Messages.ListByConversation(ctx, conv.ID)
+	if err != nil || len(messages) != 6 {
+		t.Errorf("got %d messages, %v after the failed batch, want the 6 stored before", len(messages), err)
+	}
+}
@@ -0,0 +1,81 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+func testDocuments(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	owner := createUser(t, r, "owner@kampus.ac.id")
+	other := createUser(t, r, "other@kampus.ac.id")
+
+	doc := createDocument(t, r, owner.ID, entity.StatusProcessing, entity.VisibilityPrivate)
+	if doc.ID == "" {
+		t.Fatal("Create did not assign an ID")
+	}
+
+	orphan := &entity.Document{
+		UserID:     "00000000-0000-0000-0000-000000000000",
+		Filename:   "orphan.txt",
+		MimeType:   "text/plain",
+		Status:     entity.StatusProcessing,
+		Visibility: entity.VisibilityPrivate,
+	}
+	if err := r.Documents.Create(ctx, orphan); err == nil {
+		t.Error("Create for a missing user succeeded")
+	}
+
+	found, err := r.Documents.FindByID(ctx, doc.ID)
+	if err != nil || found == nil || found.UserID != owner.ID {
+		t.Fatalf("FindByID = %+v, %v, want document %s", found, err, doc.ID)
+	}
+	found, err = r.Documents.FindByIDAndUserID(ctx, doc.ID, other.ID)
+	if err != nil || found != nil {
+		t.Errorf("FindByIDAndUserID of another user = %+v, %v, want nil, nil", found, err)
+	}
+	found, err = r.Documents.FindByID(ctx, "00000000-0000-0000-0000-000000000000")
+	if err != nil || found != nil {
+		t.Errorf("FindByID of a missing document = %+v, %v, want nil, nil", found, err)
+	}
+
+	if err := r.Documents.UpdateStatus(ctx, doc.ID, entity.StatusCompleted); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if err := r.Documents.UpdateTotalChunks(ctx, doc.ID, 3); err != nil {
+		t.Fatalf("UpdateTotalChunks: %v", err)
+	}
+	found, err = r.Documents.FindByIDAndUserID(ctx, doc.ID, owner.ID)
+	if err != nil || found == nil {
+		t.Fatalf("FindByIDAndUserID = %+v, %v", found, err)
+	}
+	if found.Status != entity.StatusCompleted || found.TotalChunks != 3 {
+		t.Errorf("got status %s with %d chunks, want COMPLETED with 3", found.Status, found.TotalChunks)
+	}
+
+	createDocument(t, r, owner.ID, entity.StatusProcessing, entity.VisibilityPublic)
+	createDocument(t, r, other.ID, entity.StatusProcessing, entity.VisibilityPublic)
+	docs, total, err := r.Documents.List(ctx, owner.ID, 1, 1)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 2 || len(docs) != 1 {
+		t.Errorf("List returned %d documents of %d, want 1 of 2", len(docs), total)
+	}
+
+	createChunks(t, r, doc.ID, vector(1), vector(1))
+	if err := r.Documents.Delete(ctx, doc.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	found, err = r.Documents.FindByID(ctx, doc.ID)
+	if err != nil || found != nil {
+		t.Errorf("FindByID after Delete = %+v, %v, want nil, nil", found, err)
+	}
+	chunks, err := r.Chunks.ListByRanges(ctx, owner.ID, []repository.ChunkRange{{DocumentID: doc.ID, From: 0, To: 10}})
+	if err != nil || len(chunks) != 0 {
+		t.Errorf("chunks after Delete = %d, %v, want them deleted with the document", len(chunks), err)
+	}
+}
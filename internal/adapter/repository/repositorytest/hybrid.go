@@ -0,0 +1,77 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+func testSearchHybrid(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+
+	chunks := []entity.DocumentChunk{
+		// the best vector match, without the course code
+		{DocumentID: doc.ID, ChunkIndex: 0, Content: "struktur data dan algoritma", Embedding: vector(1)},
+		// matches both rankings
+		{DocumentID: doc.ID, ChunkIndex: 1, Content: "silabus IF2110 struktur data", Embedding: vector(0.8, 0.6)},
+		// only a keyword match, far below the similarity threshold
+		{DocumentID: doc.ID, ChunkIndex: 2, Content: "jadwal ujian IF2110", Embedding: vector(0, 1)},
+		// matches neither
+		{DocumentID: doc.ID, ChunkIndex: 3, Content: "tata tertib laboratorium", Embedding: vector(0, 0, 1)},
+	}
+	for i := range chunks {
+		chunks[i].Metadata = []byte(`{}`)
+	}
+	if err := r.Chunks.CreateBatch(ctx, chunks); err != nil {
+		t.Fatalf("create chunks: %v", err)
+	}
+	filter := repository.ChunkSearchFilter{UserID: user.ID, TopK: 5, Threshold: 0.5}
+
+	found, err := r.Chunks.SearchHybrid(ctx, vector(1), hybridSearch("if2110"), filter)
+	if err != nil {
+		t.Fatalf("SearchHybrid: %v", err)
+	}
+	if got := chunkIDs(found); !equalStrings(got, chunks[1].ID, chunks[0].ID, chunks[2].ID) {
+		t.Fatalf("got chunks %v, want the chunk in both rankings, then the vector match, then the keyword match %v",
+			got, []string{chunks[1].ID, chunks[0].ID, chunks[2].ID})
+	}
+
+	// the shorter keyword match ranks first by keywords, so the chunk ranked
+	// second in both fuses to 2/62 and the two others to 1/61, with the more
+	// similar one first
+	if !near(found[0].Score, 2.0/62) || !near(found[1].Score, 1.0/61) || !near(found[2].Score, 1.0/61) {
+		t.Errorf("got scores %v, %v and %v, want the reciprocal rank fusion", found[0].Score, found[1].Score, found[2].Score)
+	}
+	if !near(found[2].Similarity, 0) {
+		t.Errorf("got similarity %v for the keyword match, want its cosine similarity 0", found[2].Similarity)
+	}
+
+	// weighing the keywords only puts the keyword matches first
+	search := hybridSearch("if2110")
+	search.VectorWeight = 0
+	found, err = r.Chunks.SearchHybrid(ctx, vector(1), search, filter)
+	if err != nil {
+		t.Fatalf("SearchHybrid: %v", err)
+	}
+	if got := chunkIDs(found); len(got) != 3 || got[2] != chunks[0].ID {
+		t.Errorf("got chunks %v without vector weight, want %s last", got, chunks[0].ID)
+	}
+
+	// TopK applies to the fused ranking
+	filter.TopK = 1
+	found, err = r.Chunks.SearchHybrid(ctx, vector(1), hybridSearch("if2110"), filter)
+	if err != nil {
+		t.Fatalf("SearchHybrid: %v", err)
+	}
+	if got := chunkIDs(found); !equalStrings(got, chunks[1].ID) {
+		t.Errorf("got chunks %v with TopK 1, want %v", got, []string{chunks[1].ID})
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
@@ -0,0 +1,115 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+func testEnqueueIfIdle(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusCompleted, entity.VisibilityPrivate)
+
+	// concurrent requests queue the document exactly once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	queued := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := r.Jobs.EnqueueIfIdle(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType})
+			if err != nil {
+				t.Errorf("EnqueueIfIdle: %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				queued++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if queued != 1 {
+		t.Fatalf("queued %d jobs for one document, want 1", queued)
+	}
+
+	if err := r.Jobs.Enqueue(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err == nil {
+		t.Error("Enqueue of a second active job succeeded")
+	}
+
+	// a running job is still active, a completed one isn't
+	job, err := r.Jobs.Claim(ctx)
+	if err != nil || job == nil {
+		t.Fatalf("Claim = %v, %v", job, err)
+	}
+	if ok, err := r.Jobs.EnqueueIfIdle(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err != nil || ok {
+		t.Errorf("EnqueueIfIdle with a running job = %v, %v, want false", ok, err)
+	}
+	if err := r.Jobs.Complete(ctx, job); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if ok, err := r.Jobs.EnqueueIfIdle(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err != nil || !ok {
+		t.Errorf("EnqueueIfIdle after completing = %v, %v, want true", ok, err)
+	}
+}
+
+// testJobClaims checks that a heartbeat keeps a running job from going stale
+// and that a recovered job only answers to its new claim
+func testJobClaims(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	doc := createDocument(t, r, user.ID, entity.StatusProcessing, entity.VisibilityPrivate)
+	if err := r.Jobs.Enqueue(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	first, err := r.Jobs.Claim(ctx)
+	if err != nil || first == nil {
+		t.Fatalf("Claim = %v, %v", first, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := r.Jobs.Heartbeat(ctx, first); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if recovered, err := r.Jobs.RecoverStale(ctx, 30*time.Millisecond); err != nil || recovered != 0 {
+		t.Errorf("RecoverStale after a heartbeat = %d, %v, want 0", recovered, err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if recovered, err := r.Jobs.RecoverStale(ctx, 30*time.Millisecond); err != nil || recovered != 1 {
+		t.Fatalf("RecoverStale without a heartbeat = %d, %v, want 1", recovered, err)
+	}
+	second, err := r.Jobs.Claim(ctx)
+	if err != nil || second == nil {
+		t.Fatalf("Claim of the recovered job = %v, %v", second, err)
+	}
+
+	// the first claim is gone, whatever it tries
+	if err := r.Jobs.Heartbeat(ctx, first); !errors.Is(err, repository.ErrJobNotClaimed) {
+		t.Errorf("Heartbeat of the old claim = %v, want ErrJobNotClaimed", err)
+	}
+	if err := r.Jobs.Complete(ctx, first); !errors.Is(err, repository.ErrJobNotClaimed) {
+		t.Errorf("Complete of the old claim = %v, want ErrJobNotClaimed", err)
+	}
+	if err := r.Jobs.Retry(ctx, first, 0, "retry"); !errors.Is(err, repository.ErrJobNotClaimed) {
+		t.Errorf("Retry of the old claim = %v, want ErrJobNotClaimed", err)
+	}
+	if err := r.Jobs.Fail(ctx, first, "fail"); !errors.Is(err, repository.ErrJobNotClaimed) {
+		t.Errorf("Fail of the old claim = %v, want ErrJobNotClaimed", err)
+	}
+
+	if err := r.Jobs.Complete(ctx, second); err != nil {
+		t.Fatalf("Complete of the new claim: %v", err)
+	}
+	if err := r.Jobs.Complete(ctx, second); !errors.Is(err, repository.ErrJobNotClaimed) {
+		t.Errorf("Complete of a completed job = %v, want ErrJobNotClaimed", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package repositorytest is the conformance suite that every implementation
+// of the repository interfaces must pass, so the memory adapter keeps the
+// semantics of the postgres one.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// Dimensions matches the vector(1536) column of document_chunks
+const Dimensions = 1536
+
+// Repositories are the implementations under test, sharing one store
+type Repositories struct {
+	Users         repository.UserRepository
+	Documents     repository.DocumentRepository
+	Chunks        repository.ChunkRepository
+	Jobs          repository.JobRepository
+	Conversations repository.ConversationRepository
+	Messages      repository.MessageRepository
+}
+
+// Run runs the whole suite. newRepositories is called for every test and must
+// return repositories over an empty store.
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r Repositories)
+	}{
+		{"Users", testUsers},
+		{"Documents", testDocuments},
+		{"ChunkConstraints", testChunkConstraints},
+		{"SearchSimilar", testSearchSimilar},
+		{"SearchFilters", testSearchFilters},
+		{"SearchAccess", testSearchAccess},
+		{"SearchHybrid", testSearchHybrid},
+		{"ReplaceAndDeleteChunks", testReplaceAndDeleteChunks},
+		{"ListByRanges", testListByRanges},
+		{"ListByRangesAccess", testListByRangesAccess},
+		{"EnqueueIfIdle", testEnqueueIfIdle},
+		{"JobClaims", testJobClaims},
+		{"Conversations", testConversations},
+		{"Messages", testMessages},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newRepositories(t))
+		})
+	}
+}
+
+// vector pads values with zeros to Dimensions
+func vector(values ...float32) pgvector.Vector {
+	padded := make([]float32, Dimensions)
+	copy(padded, values)
+	return pgvector.NewVector(padded)
+}
+
+func createUser(t *testing.T, r Repositories, email string) *entity.User {
+	t.Helper()
+	user := &entity.User{
+		Email:    email,
+		Password: "hashed",
+		Name:     email,
+		Major:    "Informatika",
+		// the UserRole enum of the migrations is uppercase
+		Role: entity.UserRole("STUDENT"),
+	}
+	if err := r.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func createDocument(t *testing.T, r Repositories, userID string, status entity.DocumentStatus, visibility entity.DocumentVisibility) *entity.Document {
+	t.Helper()
+	doc := &entity.Document{
+		UserID:       userID,
+		Filename:     "materi.txt",
+		OriginalName: "materi.txt",
+		FileSize:     100,
+		MimeType:     "text/plain",
+		Status:       status,
+		Visibility:   visibility,
+	}
+	if err := r.Documents.Create(context.Background(), doc); err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	return doc
+}
+
+// createChunks stores one chunk per embedding, indexed from 0
+func createChunks(t *testing.T, r Repositories, documentID string, embeddings ...pgvector.Vector) []entity.DocumentChunk {
+	t.Helper()
+	chunks := make([]entity.DocumentChunk, len(embeddings))
+	for i, embedding := range embeddings {
+		chunks[i] = entity.DocumentChunk{
+			DocumentID: documentID,
+			ChunkIndex: i,
+			Content:    fmt.Sprintf("chunk %d", i),
+			Embedding:  embedding,
+			Metadata:   []byte(`{"source":"text"}`),
+		}
+	}
+	if err := r.Chunks.CreateBatch(context.Background(), chunks); err != nil {
+		t.Fatalf("create chunks: %v", err)
+	}
+	return chunks
+}
+
+func chunkIDs(chunks []entity.SimilarChunk) []string {
+	ids := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.ID
+	}
+	return ids
+}
+
+func equalStrings(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"rag-api/internal/domain/entity"
+)
+
+func testUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	user := createUser(t, r, "budi@kampus.ac.id")
+	if user.ID == "" {
+		t.Fatal("Create did not assign an ID")
+	}
+
+	found, err := r.Users.FindByEmail(ctx, "budi@kampus.ac.id")
+	if err != nil || found == nil || found.ID != user.ID {
+		t.Fatalf("FindByEmail = %+v, %v, want user %s", found, err, user.ID)
+	}
+	found, err = r.Users.FindById(ctx, user.ID)
+	if err != nil || found == nil || found.Email != user.Email {
+		t.Fatalf("FindById = %+v, %v, want user %s", found, err, user.ID)
+	}
+
+	duplicate := &entity.User{Email: user.Email, Password: "hashed", Name: "Budi", Major: "Informatika", Role: user.Role}
+	if err := r.Users.Create(ctx, duplicate); err == nil {
+		t.Error("Create with a taken email succeeded")
+	}
+
+	if _, err := r.Users.FindByEmail(ctx, "missing@kampus.ac.id"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByEmail of a missing user returned %v, want sql.ErrNoRows", err)
+	}
+	if _, err := r.Users.FindById(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindById of a missing user returned %v, want sql.ErrNoRows", err)
+	}
+}
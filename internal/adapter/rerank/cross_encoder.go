@@ -0,0 +1,87 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CrossEncoder scores documents with a local cross-encoder model, e.g.
+// BAAI/bge-reranker-v2-m3, served by Hugging Face text-embeddings-inference.
+// The model reads the query and a document together, which ranks more
+// precisely than comparing their embeddings.
+type CrossEncoder struct {
+	url    string
+	client *http.Client
+}
+
+// NewCrossEncoder sends requests to the /rerank endpoint of the server at baseURL
+func NewCrossEncoder(baseURL string) (*CrossEncoder, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("RERANKER_URL is required for the cross-encoder reranker")
+	}
+	return &CrossEncoder{
+		url:    strings.TrimSuffix(baseURL, "/") + "/rerank",
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type rerankRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+type rerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+// rerank, scores are between 0 and 1. Documents longer than the model's input
+// are truncated.
+func (c *CrossEncoder) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	body, err := json.Marshal(rerankRequest{Query: query, Texts: documents, Truncate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rerank request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	// results are sorted by score, not in the order of the documents
+	var results []rerankResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("invalid rerank response: %w", err)
+	}
+	if len(results) != len(documents) {
+		return nil, fmt.Errorf("got %d rerank scores, expected %d", len(results), len(documents))
+	}
+
+	scores := make([]float64, len(documents))
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, fmt.Errorf("invalid rerank result index %d", result.Index)
+		}
+		scores[result.Index] = result.Score
+	}
+	return scores, nil
+}
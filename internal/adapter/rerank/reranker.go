@@ -0,0 +1,32 @@
+package rerank
+
+import (
+	"fmt"
+	"strings"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/adapter/llm"
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/config"
+)
+
+// New builds the reranker selected by cfg.Reranker, nil when reranking is
+// disabled. The llm reranker uses the chat model of provider.
+func New(cfg *config.Config, provider *llm.Provider) (document.Reranker, error) {
+	switch strings.ToLower(cfg.Reranker) {
+	case "", "none":
+		return nil, nil
+	case "llm":
+		reranker, ok := provider.Chat.(document.Reranker)
+		if !ok {
+			return nil, fmt.Errorf("the %s provider cannot rerank", cfg.LLMProvider)
+		}
+		return reranker, nil
+	case "cross-encoder":
+		return NewCrossEncoder(cfg.RerankerURL)
+	case "fake":
+		return fake.NewReranker(), nil
+	default:
+		return nil, fmt.Errorf("unknown reranker %q, available: none, llm, cross-encoder, fake", cfg.Reranker)
+	}
+}
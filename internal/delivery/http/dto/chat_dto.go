@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+type CreateConversationRequest struct {
+	Title string `json:"title" example:"Statistika Dasar"`
+}
+
+type RenameConversationRequest struct {
+	Title string `json:"title" binding:"required" example:"Statistika Dasar"`
+}
+
+type SendMessageRequest struct {
+	Message       string `json:"message" binding:"required" example:"Apa itu regresi linear?"`
+	CondenseQuery *bool  `json:"condenseQuery,omitempty" example:"true"`
+}
+
+type ConversationInfo struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ListConversationsResponse struct {
+	Data []ConversationInfo `json:"data"`
+	Meta PaginationMeta     `json:"meta"`
+}
+
+type MessageSourceInfo struct {
+	ChunkID        string   `json:"chunkId"`
+	DocumentID     string   `json:"documentId"`
+	ChunkIndex     int      `json:"chunkIndex"`
+	Similarity     float64  `json:"similarity"`
+	StartPage      int      `json:"startPage,omitempty" example:"3"`
+	EndPage        int      `json:"endPage,omitempty" example:"4"`
+	SlideNumber    int      `json:"slideNumber,omitempty" example:"14"`
+	EndSlideNumber int      `json:"endSlideNumber,omitempty" example:"14"`
+	HeadingPath    []string `json:"headingPath,omitempty" example:"Bab 1,Pendahuluan"`
+	SheetName      string   `json:"sheetName,omitempty" example:"Nilai"`
+	StartRow       int      `json:"startRow,omitempty" example:"2"`
+	EndRow         int      `json:"endRow,omitempty" example:"40"`
+}
+
+type ChatMessageInfo struct {
+	ID        string              `json:"id"`
+	Role      string              `json:"role" example:"USER"`
+	Content   string              `json:"content"`
+	Sources   []MessageSourceInfo `json:"sources,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+type ConversationDetail struct {
+	Conversation ConversationInfo  `json:"conversation"`
+	Messages     []ChatMessageInfo `json:"messages"`
+}
+
+type SendMessageResponse struct {
+	ConversationID   string          `json:"conversationId"`
+	SearchQuery      string          `json:"searchQuery"`
+	UserMessage      ChatMessageInfo `json:"userMessage"`
+	AssistantMessage ChatMessageInfo `json:"assistantMessage"`
+	Sources          []ChunkSource   `json:"sources"`
+}
@@ -1,53 +1,124 @@
-package dto
-
-import "time"
-
-type UploadDocumentResponse struct {
-	ID       string `json:"id"`
-	Filename string `json:"filename"`
-	Status   string `json:"status"`
-	Message  string `json:"message"`
-}
-
-type DocumentInfo struct {
-	ID           string    `json:"id"`
-	Filename     string    `json:"filename"`
-	OriginalName string    `json:"originalName"`
-	FileSize     int64     `json:"fileSize"`
-	MimeType     string    `json:"mimeType"`
-	Status       string    `json:"status"`
-	TotalChunks  int       `json:"totalChunks"`
-	Visibility   string    `json:"visibility"`
-	CreatedAt    time.Time `json:"createdAt"`
-}
-
-type ListDocumentsResponse struct {
-	Data []DocumentInfo `json:"data"`
-	Meta PaginationMeta `json:"meta"`
-}
-
-
-type PaginationMeta struct {
-	Total    int `json:"total"`
-	Page     int `json:"page"`
-	Limit    int `json:"limit"`
-	TotalPages int `json:"totalPages"`
-}	
-
-
-type QueryDocumentRequest struct {
-	Query string `json:"query" binding:"required"`
-}
-
-type QueryDocumentResponse struct {
-	Query   string        `json:"query"`
-	Answer  string        `json:"answer"`
-	Sources []ChunkSource `json:"sources"`
-}
-
-type ChunkSource struct {
-	DocumentID string  `json:"documentId"`
-	Content    string  `json:"content"`
-	Similarity float64 `json:"similarity"`
-	ChunkIndex int     `json:"chunkIndex"`
-}
\ No newline at end of file
+package dto
+
+import "time"
+
+type UploadDocumentResponse struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+type ReprocessDocumentResponse struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type ReindexResponse struct {
+	Queued  int `json:"queued"`
+	Skipped int `json:"skipped"`
+}
+
+type DocumentInfo struct {
+	ID               string    `json:"id"`
+	Filename         string    `json:"filename"`
+	OriginalName     string    `json:"originalName"`
+	FileSize         int64     `json:"fileSize"`
+	MimeType         string    `json:"mimeType"`
+	Status           string    `json:"status"`
+	TotalChunks      int       `json:"totalChunks"`
+	Visibility       string    `json:"visibility"`
+	ChunkingStrategy string    `json:"chunkingStrategy,omitempty" example:"recursive"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type ListDocumentsResponse struct {
+	Data []DocumentInfo `json:"data"`
+	Meta PaginationMeta `json:"meta"`
+}
+
+
+type PaginationMeta struct {
+	Total    int `json:"total"`
+	Page     int `json:"page"`
+	Limit    int `json:"limit"`
+	TotalPages int `json:"totalPages"`
+}	
+
+
+type QueryDocumentRequest struct {
+	Query       string     `json:"query" binding:"required"`
+	DocumentIDs []string   `json:"documentIds,omitempty"`
+	MimeTypes   []string   `json:"mimeTypes,omitempty" example:"application/pdf"`
+	UploadedBy  string     `json:"uploadedBy,omitempty"`
+	CreatedFrom *time.Time `json:"createdFrom,omitempty"`
+	CreatedTo   *time.Time `json:"createdTo,omitempty"`
+
+	// previous conversation turns, used to condense a follow-up question
+	History       []ChatTurn `json:"history,omitempty"`
+	CondenseQuery *bool      `json:"condenseQuery,omitempty" example:"true"`
+
+	// vector or hybrid search, the server default when empty. The fusion
+	// weights only apply to hybrid searches.
+	SearchMode    string   `json:"searchMode,omitempty" example:"hybrid" enums:"vector,hybrid"`
+	VectorWeight  *float64 `json:"vectorWeight,omitempty" example:"1"`
+	KeywordWeight *float64 `json:"keywordWeight,omitempty" example:"1"`
+	// relevance against diversity of the sources, 1 turns MMR off
+	MMRLambda *float64 `json:"mmrLambda,omitempty" example:"0.7"`
+	// chunks before and after every hit added to its context, at most 5
+	NeighbourChunks *int `json:"neighbourChunks,omitempty" example:"1"`
+	// none, multi-query or hyde, the server default when empty
+	Expansion string `json:"expansion,omitempty" example:"multi-query" enums:"none,multi-query,hyde"`
+}
+
+type ChatTurn struct {
+	Role    string `json:"role" example:"USER" enums:"USER,ASSISTANT"`
+	Content string `json:"content"`
+}
+
+type QueryDocumentResponse struct {
+	Query       string        `json:"query"`
+	SearchQuery string        `json:"searchQuery"`
+	Expansions  []string      `json:"expansions,omitempty"`
+	Answer      string        `json:"answer"`
+	Sources     []ChunkSource `json:"sources"`
+}
+
+type ChunkSource struct {
+	DocumentID     string   `json:"documentId"`
+	Content        string   `json:"content"`
+	Similarity     float64  `json:"similarity"`
+	Score          float64  `json:"score,omitempty" example:"0.0325"`
+	RerankScore    *float64 `json:"rerankScore,omitempty" example:"0.91"`
+	ChunkIndex     int      `json:"chunkIndex"`
+	StartPage      int      `json:"startPage,omitempty" example:"3"`
+	EndPage        int      `json:"endPage,omitempty" example:"4"`
+	SlideNumber    int      `json:"slideNumber,omitempty" example:"14"`
+	EndSlideNumber int      `json:"endSlideNumber,omitempty" example:"14"`
+	HeadingPath    []string `json:"headingPath,omitempty" example:"Bab 1,Pendahuluan"`
+	SheetName      string   `json:"sheetName,omitempty" example:"Nilai"`
+	StartRow       int      `json:"startRow,omitempty" example:"2"`
+	EndRow         int      `json:"endRow,omitempty" example:"40"`
+}
+
+type QueryStreamSourcesEvent struct {
+	Query       string        `json:"query"`
+	SearchQuery string        `json:"searchQuery"`
+	Expansions  []string      `json:"expansions,omitempty"`
+	Sources     []ChunkSource `json:"sources"`
+}
+
+type QueryStreamDeltaEvent struct {
+	Content string `json:"content"`
+}
+
+type QueryStreamDoneEvent struct {
+	Usage TokenUsage `json:"usage"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
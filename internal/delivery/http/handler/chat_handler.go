@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"rag-api/internal/delivery/http/dto"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/chat"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChatHandler struct {
+	chatUsecase *chat.ChatUsecase
+}
+
+func NewChatHandler(chatUsecase *chat.ChatUsecase) *ChatHandler {
+	return &ChatHandler{chatUsecase: chatUsecase}
+}
+
+// CreateConversation godoc
+// @Summary      Create a conversation
+// @Description  Start a new chat conversation. The title defaults to the first question asked.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.CreateConversationRequest  false  "Create Conversation Request"
+// @Success      201      {object}  dto.ConversationInfo
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations [post]
+func (h *ChatHandler) CreateConversation(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	var req dto.CreateConversationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	conv, err := h.chatUsecase.CreateConversation(c.Context(), userID, req.Title)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(toConversationInfo(conv))
+}
+
+// ListConversations godoc
+// @Summary      List conversations
+// @Description  Get the authenticated user's conversations, most recently active first
+// @Tags         Chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page   query  int  false  "Page number" default(1)
+// @Param        limit  query  int  false  "Items per page" default(10)
+// @Success      200  {object}  dto.ListConversationsResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations [get]
+func (h *ChatHandler) ListConversations(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 || limit < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "page and limit must be positive"})
+	}
+
+	convs, total, err := h.chatUsecase.ListConversations(c.Context(), userID, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// convert to dto
+	var convInfos []dto.ConversationInfo
+	for i := range convs {
+		convInfos = append(convInfos, toConversationInfo(&convs[i]))
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.Status(fiber.StatusOK).JSON(dto.ListConversationsResponse{
+		Data: convInfos,
+		Meta: dto.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// GetConversation godoc
+// @Summary      Get conversation detail
+// @Description  Get a conversation with all of its messages
+// @Tags         Chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Conversation ID"
+// @Success      200  {object}  dto.ConversationDetail
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations/{id} [get]
+func (h *ChatHandler) GetConversation(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	conversationID := c.Params("id")
+
+	conv, messages, err := h.chatUsecase.GetConversation(c.Context(), conversationID, userID)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	msgInfos := make([]dto.ChatMessageInfo, 0, len(messages))
+	for i := range messages {
+		msgInfos = append(msgInfos, toChatMessageInfo(&messages[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ConversationDetail{
+		Conversation: toConversationInfo(conv),
+		Messages:     msgInfos,
+	})
+}
+
+// RenameConversation godoc
+// @Summary      Rename a conversation
+// @Description  Change the title of a conversation
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Conversation ID"
+// @Param        request  body      dto.RenameConversationRequest  true  "Rename Conversation Request"
+// @Success      200      {object}  dto.ConversationInfo
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations/{id} [patch]
+func (h *ChatHandler) RenameConversation(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	conversationID := c.Params("id")
+
+	var req dto.RenameConversationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "title is required"})
+	}
+
+	conv, err := h.chatUsecase.RenameConversation(c.Context(), conversationID, userID, req.Title)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(toConversationInfo(conv))
+}
+
+// DeleteConversation godoc
+// @Summary      Delete a conversation
+// @Description  Delete a conversation and all of its messages
+// @Tags         Chat
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Conversation ID"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations/{id} [delete]
+func (h *ChatHandler) DeleteConversation(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	conversationID := c.Params("id")
+
+	if err := h.chatUsecase.DeleteConversation(c.Context(), conversationID, userID); err != nil {
+		return chatError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Conversation deleted successfully"})
+}
+
+// SendMessage godoc
+// @Summary      Send a message
+// @Description  Ask a question in a conversation. Retrieval runs over your own and public documents and the recent turns are sent to the LLM as history.
+// @Description  Follow-up questions are rewritten into a standalone search query unless condenseQuery is false.
+// @Tags         Chat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Conversation ID"
+// @Param        request  body      dto.SendMessageRequest  true  "Send Message Request"
+// @Success      200      {object}  dto.SendMessageResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      404      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /api/chat/conversations/{id}/messages [post]
+func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	conversationID := c.Params("id")
+
+	var req dto.SendMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message is required"})
+	}
+
+	condenseQuery := req.CondenseQuery == nil || *req.CondenseQuery
+	userMsg, assistantMsg, retrieval, err := h.chatUsecase.SendMessage(c.Context(), conversationID, userID, req.Message, condenseQuery)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.SendMessageResponse{
+		ConversationID:   conversationID,
+		SearchQuery:      retrieval.SearchQuery,
+		UserMessage:      toChatMessageInfo(userMsg),
+		AssistantMessage: toChatMessageInfo(assistantMsg),
+		Sources:          toChunkSources(retrieval.Chunks),
+	})
+}
+
+func chatError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, chat.ErrConversationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Conversation not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+func toConversationInfo(conv *entity.Conversation) dto.ConversationInfo {
+	return dto.ConversationInfo{
+		ID:        conv.ID,
+		Title:     conv.Title,
+		CreatedAt: conv.CreatedAt,
+		UpdatedAt: conv.UpdatedAt,
+	}
+}
+
+func toChatMessageInfo(msg *entity.Message) dto.ChatMessageInfo {
+	var sources []entity.MessageSource
+	if len(msg.Sources) > 0 {
+		json.Unmarshal(msg.Sources, &sources)
+	}
+
+	var sourceInfos []dto.MessageSourceInfo
+	for _, source := range sources {
+		sourceInfos = append(sourceInfos, dto.MessageSourceInfo{
+			ChunkID:        source.ChunkID,
+			DocumentID:     source.DocumentID,
+			ChunkIndex:     source.ChunkIndex,
+			Similarity:     source.Similarity,
+			StartPage:      source.StartPage,
+			EndPage:        source.EndPage,
+			SlideNumber:    source.SlideNumber,
+			EndSlideNumber: source.EndSlideNumber,
+			HeadingPath:    source.HeadingPath,
+			SheetName:      source.SheetName,
+			StartRow:       source.StartRow,
+			EndRow:         source.EndRow,
+		})
+	}
+
+	return dto.ChatMessageInfo{
+		ID:        msg.ID,
+		Role:      string(msg.Role),
+		Content:   msg.Content,
+		Sources:   sourceInfos,
+		CreatedAt: msg.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rag-api/internal/adapter/repository/memory"
+	"rag-api/internal/usecase/chat"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestListConversationsRejectsInvalidPagination(t *testing.T) {
+	db := memory.NewDB()
+	h := NewChatHandler(chat.NewChatUsecase(memory.NewConversationRepository(db), memory.NewMessageRepository(db), nil, nil, 10))
+
+	app := fiber.New()
+	app.Get("/conversations", h.ListConversations)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", fiber.StatusOK},
+		{"?limit=0", fiber.StatusBadRequest},
+		{"?limit=-1", fiber.StatusBadRequest},
+		{"?limit=abc", fiber.StatusBadRequest},
+		{"?page=0", fiber.StatusBadRequest},
+	}
+	for _, test := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/conversations"+test.query, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", test.query, err)
+		}
+		if resp.StatusCode != test.want {
+			t.Errorf("GET /conversations%s returned %d, want %d", test.query, resp.StatusCode, test.want)
+		}
+	}
+}
+
+func TestChatHandlerRejectsBlankInput(t *testing.T) {
+	db := memory.NewDB()
+	h := NewChatHandler(chat.NewChatUsecase(memory.NewConversationRepository(db), memory.NewMessageRepository(db), nil, nil, 10))
+
+	app := fiber.New()
+	app.Patch("/conversations/:id", h.RenameConversation)
+	app.Post("/conversations/:id/messages", h.SendMessage)
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{"PATCH", "/conversations/1", `{"title": ""}`},
+		{"PATCH", "/conversations/1", `{"title": " \t\n"}`},
+		{"POST", "/conversations/1/messages", `{"message": ""}`},
+		{"POST", "/conversations/1/messages", `{"message": "   "}`},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", test.method, test.path, err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s %s with %s returned %d, want %d", test.method, test.path, test.body, resp.StatusCode, fiber.StatusBadRequest)
+		}
+	}
+}
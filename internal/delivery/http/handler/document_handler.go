@@ -1,230 +1,494 @@
-package handler
-
-import (
-	"io"
-	"rag-api/internal/delivery/http/dto"
-	"rag-api/internal/domain/entity"
-	"rag-api/internal/usecase/document"
-	"strconv"
-
-	"github.com/gofiber/fiber/v2"
-)
-
-type DocumentHandler struct {
-	docUsecase *document.DocumentUsecase
-}
-
-func NewDocumentHandler(docUsecase *document.DocumentUsecase) *DocumentHandler {
-	return &DocumentHandler{docUsecase: docUsecase}
-}
-
-// Upload godoc
-// @Summary      Upload a document
-// @Description  Upload a PDF or image file for processing
-// @Tags         Documents
-// @Accept       multipart/form-data
-// @Produce      json
-// @Security     BearerAuth
-// @Param        file        formData  file    true  "File to upload"
-// @Param        visibility  formData  string  false "Visibility (PUBLIC or PRIVATE)" default(PRIVATE)
-// @Success      201  {object}  dto.UploadDocumentResponse
-// @Failure      400  {object}  dto.ErrorResponse
-// @Failure      500  {object}  dto.ErrorResponse
-// @Router       /api/documents/upload [post]
-func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
-	userID, _ := c.Locals("userID").(string)
-
-	// get file from form
-	file, err := c.FormFile("file")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to get file"})
-	}
-
-	// get visibility from form
-	visibility := entity.VisibilityPrivate
-	if c.FormValue("visibility") == "PUBLIC" {
-		visibility = entity.VisibilityPublic
-	}
-
-	// read file data
-	fileData, err := file.Open()
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to open file"})
-	}
-	defer fileData.Close()
-
-	buf, err := io.ReadAll(fileData)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to read file"})
-	}
-
-	// upload document
-	doc, err := h.docUsecase.UploadDocument(
-		c.Context(),
-		userID,
-		file.Filename,
-		buf,
-		file.Header.Get("Content-Type"),
-		visibility,
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.Status(fiber.StatusCreated).JSON(dto.UploadDocumentResponse{
-		ID:       doc.ID,
-		Filename: doc.Filename,
-		Status:   string(doc.Status),
-		Message:  "Document uploaded successfully. Processing in background.",
-	})
-}
-
-// List godoc
-// @Summary      List documents
-// @Description  Get a list of documents for the authenticated user
-// @Tags         Documents
-// @Produce      json
-// @Security     BearerAuth
-// @Param        page   query  int  false  "Page number" default(1)
-// @Param        limit  query  int  false  "Items per page" default(10)
-// @Success      200  {object}  dto.ListDocumentsResponse
-// @Failure      500  {object}  dto.ErrorResponse
-// @Router       /api/documents [get]
-func (h *DocumentHandler) List(c *fiber.Ctx) error {
-	userID, _ := c.Locals("userID").(string)
-
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-
-	docs, total, err := h.docUsecase.ListDocuments(c.Context(), userID, page, limit)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	// convert to dto
-	var docInfos []dto.DocumentInfo
-	for _, doc := range docs {
-		docInfos = append(docInfos, dto.DocumentInfo{
-			ID:           doc.ID,
-			Filename:     doc.Filename,
-			OriginalName: doc.OriginalName,
-			FileSize:     doc.FileSize,
-			MimeType:     doc.MimeType,
-			Status:       string(doc.Status),
-			TotalChunks:  doc.TotalChunks,
-			Visibility:   string(doc.Visibility),
-			CreatedAt:    doc.CreatedAt,
-		})
-	}
-
-	totalPages := (total + limit - 1) / limit
-
-	return c.Status(fiber.StatusOK).JSON(dto.ListDocumentsResponse{
-		Data: docInfos,
-		Meta: dto.PaginationMeta{
-			Total:      total,
-			Page:       page,
-			Limit:      limit,
-			TotalPages: totalPages,
-		},
-	})
-
-}
-
-// GetByID godoc
-// @Summary      Get document by ID
-// @Description  Get a single document's details
-// @Tags         Documents
-// @Produce      json
-// @Security     BearerAuth
-// @Param        id  path  string  true  "Document ID"
-// @Success      200  {object}  dto.DocumentInfo
-// @Failure      404  {object}  dto.ErrorResponse
-// @Failure      500  {object}  dto.ErrorResponse
-// @Router       /api/documents/{id} [get]
-func (h *DocumentHandler) GetByID(c *fiber.Ctx) error {
-	userId, _ := c.Locals("userID").(string)
-	documentID := c.Params("id")
-
-	doc, err := h.docUsecase.GetDocumentByID(c.Context(), documentID, userId)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-	if doc == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Document not found"})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(dto.DocumentInfo{
-		ID:           doc.ID,
-		Filename:     doc.Filename,
-		OriginalName: doc.OriginalName,
-		FileSize:     doc.FileSize,
-		MimeType:     doc.MimeType,
-		Status:       string(doc.Status),
-		TotalChunks:  doc.TotalChunks,
-		Visibility:   string(doc.Visibility),
-		CreatedAt:    doc.CreatedAt,
-	})
-}
-
-// Delete godoc
-// @Summary      Delete a document
-// @Description  Delete a document by ID
-// @Tags         Documents
-// @Produce      json
-// @Security     BearerAuth
-// @Param        id  path  string  true  "Document ID"
-// @Success      200  {object}  dto.MessageResponse
-// @Failure      500  {object}  dto.ErrorResponse
-// @Router       /api/documents/{id} [delete]
-func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
-	userID, _ := c.Locals("userID").(string)
-	documentID := c.Params("id")
-
-	if err := h.docUsecase.DeleteDocument(c.Context(), documentID, userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Document deleted successfully"})
-}
-
-// Query godoc
-// @Summary      Query documents with RAG
-// @Description  Search documents using natural language and get AI-generated answer
-// @Tags         Documents
-// @Accept       json
-// @Produce      json
-// @Security     BearerAuth
-// @Param        request  body      dto.QueryDocumentRequest  true  "Query Request"
-// @Success      200      {object}  dto.QueryDocumentResponse
-// @Failure      400      {object}  dto.ErrorResponse
-// @Failure      500      {object}  dto.ErrorResponse
-// @Router       /api/documents/query [post]
-func (h *DocumentHandler) Query(c *fiber.Ctx) error {
-	var req dto.QueryDocumentRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	answer, chunks, err := h.docUsecase.QueryDocuments(c.Context(), req.Query)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	// Convert chunks to sources
-	var sources []dto.ChunkSource
-	for _, chunk := range chunks {
-		sources = append(sources, dto.ChunkSource{
-			DocumentID: chunk.DocumentID,
-			Content:    chunk.Content,
-			Similarity: chunk.Similarity,
-			ChunkIndex: chunk.ChunkIndex,
-		})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(dto.QueryDocumentResponse{
-		Query:   req.Query,
-		Answer:  answer,
-		Sources: sources,
-	})
-}
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"rag-api/internal/delivery/http/dto"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/document"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DocumentHandler struct {
+	docUsecase *document.DocumentUsecase
+}
+
+func NewDocumentHandler(docUsecase *document.DocumentUsecase) *DocumentHandler {
+	return &DocumentHandler{docUsecase: docUsecase}
+}
+
+// Upload godoc
+// @Summary      Upload a document
+// @Description  Upload a PDF, DOCX, PPTX, plain text, Markdown, HTML, CSV or XLSX file for processing. PNG, JPEG and TIFF images are accepted when OCR is enabled, which also reads scanned PDF pages
+// @Tags         Documents
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file        formData  file    true  "File to upload"
+// @Param        visibility  formData  string  false "Visibility (PUBLIC or PRIVATE)" default(PRIVATE)
+// @Param        chunkingStrategy  formData  string  false "Chunking strategy (fixed, recursive or semantic), the server default when empty"
+// @Success      201  {object}  dto.UploadDocumentResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents/upload [post]
+func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	// get file from form
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to get file"})
+	}
+
+	// get visibility from form
+	visibility := entity.VisibilityPrivate
+	if c.FormValue("visibility") == "PUBLIC" {
+		visibility = entity.VisibilityPublic
+	}
+
+	// read file data
+	fileData, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to open file"})
+	}
+	defer fileData.Close()
+
+	buf, err := io.ReadAll(fileData)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to read file"})
+	}
+
+	// upload document
+	doc, err := h.docUsecase.UploadDocument(
+		c.Context(),
+		userID,
+		file.Filename,
+		buf,
+		file.Header.Get("Content-Type"),
+		visibility,
+		c.FormValue("chunkingStrategy"),
+	)
+	if errors.Is(err, document.ErrUnsupportedFileType) || errors.Is(err, document.ErrUnknownChunkingStrategy) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.UploadDocumentResponse{
+		ID:       doc.ID,
+		Filename: doc.Filename,
+		Status:   string(doc.Status),
+		Message:  "Document uploaded successfully. Processing in background.",
+	})
+}
+
+// List godoc
+// @Summary      List documents
+// @Description  Get a list of documents for the authenticated user
+// @Tags         Documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page   query  int  false  "Page number" default(1)
+// @Param        limit  query  int  false  "Items per page" default(10)
+// @Success      200  {object}  dto.ListDocumentsResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents [get]
+func (h *DocumentHandler) List(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	docs, total, err := h.docUsecase.ListDocuments(c.Context(), userID, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// convert to dto
+	var docInfos []dto.DocumentInfo
+	for _, doc := range docs {
+		docInfos = append(docInfos, dto.DocumentInfo{
+			ID:               doc.ID,
+			Filename:         doc.Filename,
+			OriginalName:     doc.OriginalName,
+			FileSize:         doc.FileSize,
+			MimeType:         doc.MimeType,
+			Status:           string(doc.Status),
+			TotalChunks:      doc.TotalChunks,
+			Visibility:       string(doc.Visibility),
+			ChunkingStrategy: doc.ChunkingStrategy,
+			CreatedAt:        doc.CreatedAt,
+		})
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.Status(fiber.StatusOK).JSON(dto.ListDocumentsResponse{
+		Data: docInfos,
+		Meta: dto.PaginationMeta{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: totalPages,
+		},
+	})
+
+}
+
+// GetByID godoc
+// @Summary      Get document by ID
+// @Description  Get a single document's details
+// @Tags         Documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Document ID"
+// @Success      200  {object}  dto.DocumentInfo
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents/{id} [get]
+func (h *DocumentHandler) GetByID(c *fiber.Ctx) error {
+	userId, _ := c.Locals("userID").(string)
+	documentID := c.Params("id")
+
+	doc, err := h.docUsecase.GetDocumentByID(c.Context(), documentID, userId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if doc == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Document not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.DocumentInfo{
+		ID:               doc.ID,
+		Filename:         doc.Filename,
+		OriginalName:     doc.OriginalName,
+		FileSize:         doc.FileSize,
+		MimeType:         doc.MimeType,
+		Status:           string(doc.Status),
+		TotalChunks:      doc.TotalChunks,
+		Visibility:       string(doc.Visibility),
+		ChunkingStrategy: doc.ChunkingStrategy,
+		CreatedAt:        doc.CreatedAt,
+	})
+}
+
+// Delete godoc
+// @Summary      Delete a document
+// @Description  Delete a document by ID
+// @Tags         Documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Document ID"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents/{id} [delete]
+func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	documentID := c.Params("id")
+
+	if err := h.docUsecase.DeleteDocument(c.Context(), documentID, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Document deleted successfully"})
+}
+
+// Download godoc
+// @Summary      Download the original file of a document
+// @Description  Download the file exactly as it was uploaded
+// @Tags         Documents
+// @Produce      application/octet-stream
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Document ID"
+// @Success      200  {file}    file
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents/{id}/download [get]
+func (h *DocumentHandler) Download(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	documentID := c.Params("id")
+
+	doc, data, err := h.docUsecase.DownloadDocument(c.Context(), documentID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if doc == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Document not found"})
+	}
+	if data == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Original file is not available"})
+	}
+
+	c.Set(fiber.HeaderContentType, doc.MimeType)
+	c.Attachment(doc.OriginalName)
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// Reprocess godoc
+// @Summary      Reprocess a document
+// @Description  Process the stored original file again with the current chunking and embedding settings.
+// @Description  A completed document stays searchable with its old chunks until the new ones are swapped in.
+// @Tags         Documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Document ID"
+// @Success      202  {object}  dto.ReprocessDocumentResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/documents/{id}/reprocess [post]
+func (h *DocumentHandler) Reprocess(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+	documentID := c.Params("id")
+
+	doc, err := h.docUsecase.ReprocessDocument(c.Context(), documentID, userID)
+	switch {
+	case errors.Is(err, document.ErrDocumentNotFound), errors.Is(err, document.ErrOriginalFileNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, document.ErrDocumentBusy):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.ReprocessDocumentResponse{
+		ID:      doc.ID,
+		Status:  string(doc.Status),
+		Message: "Document queued for reprocessing.",
+	})
+}
+
+// Reindex godoc
+// @Summary      Reindex all documents
+// @Description  Queue every document with a stored original file for reprocessing, e.g. after changing CHUNK_SIZE_TOKENS or the embedding model.
+// @Description  Documents that are already queued or have no stored file are skipped. Admin only.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  dto.ReindexResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /api/admin/documents/reindex [post]
+func (h *DocumentHandler) Reindex(c *fiber.Ctx) error {
+	result, err := h.docUsecase.ReindexAll(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.ReindexResponse{
+		Queued:  result.Queued,
+		Skipped: result.Skipped,
+	})
+}
+
+// Query godoc
+// @Summary      Query documents with RAG
+// @Description  Search your own and public documents using natural language and get AI-generated answer.
+// @Description  When history is sent, the query is first rewritten into a standalone search query unless condenseQuery is false.
+// @Description  searchMode hybrid also matches the words of the query and fuses both rankings, weighted by vectorWeight and keywordWeight.
+// @Description  mmrLambda below 1 skips near-duplicate chunks in favour of more distinct sources.
+// @Description  neighbourChunks adds that many chunks before and after every source to its content; touching windows are merged.
+// @Description  expansion multi-query also searches LLM paraphrases of the query, hyde a hypothetical answer; all rankings are fused and the texts returned as expansions.
+// @Tags         Documents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.QueryDocumentRequest  true  "Query Request"
+// @Success      200      {object}  dto.QueryDocumentResponse
+// @Failure      400      {object}  dto.ErrorResponse
+// @Failure      500      {object}  dto.ErrorResponse
+// @Router       /api/documents/query [post]
+func (h *DocumentHandler) Query(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	var req dto.QueryDocumentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.validateSearch(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result, err := h.docUsecase.QueryDocuments(c.Context(), userID, req.Query, toQueryOptions(req))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.QueryDocumentResponse{
+		Query:       req.Query,
+		SearchQuery: result.SearchQuery,
+		Expansions:  result.Expansions,
+		Answer:      result.Answer,
+		Sources:     toChunkSources(result.Chunks),
+	})
+}
+
+// QueryStream godoc
+// @Summary      Query documents with RAG, streaming the answer
+// @Description  Same as /api/documents/query, but the answer is streamed as Server-Sent Events.
+// @Description  Events are sent in order: "sources" once, "delta" for every piece of the answer, then "done" with token usage.
+// @Description  An "error" event is sent instead if the query fails. Closing the connection cancels generation.
+// @Tags         Documents
+// @Accept       json
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        request  body      dto.QueryDocumentRequest  true  "Query Request"
+// @Success      200      {object}  dto.QueryStreamSourcesEvent  "sources event; followed by dto.QueryStreamDeltaEvent and dto.QueryStreamDoneEvent"
+// @Failure      400      {object}  dto.ErrorResponse
+// @Router       /api/documents/query/stream [post]
+func (h *DocumentHandler) QueryStream(c *fiber.Ctx) error {
+	userID, _ := c.Locals("userID").(string)
+
+	var req dto.QueryDocumentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.validateSearch(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	opts := toQueryOptions(req)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// the writer runs after the handler returns, so it gets its own context
+	// that is cancelled once a write fails because the client went away
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		send := func(event string, data interface{}) error {
+			if err := writeSSE(w, event, data); err != nil {
+				cancel()
+				return err
+			}
+			return nil
+		}
+
+		usage, err := h.docUsecase.StreamQueryDocuments(ctx, userID, req.Query, opts, document.QueryStreamHandler{
+			OnRetrieved: func(result *document.RetrievalResult) error {
+				return send("sources", dto.QueryStreamSourcesEvent{
+					Query:       req.Query,
+					SearchQuery: result.SearchQuery,
+					Expansions:  result.Expansions,
+					Sources:     toChunkSources(result.Chunks),
+				})
+			},
+			OnDelta: func(delta string) error {
+				return send("delta", dto.QueryStreamDeltaEvent{Content: delta})
+			},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				send("error", dto.ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		send("done", dto.QueryStreamDoneEvent{Usage: dto.TokenUsage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+		}})
+	})
+
+	return nil
+}
+
+// writeSSE writes one Server-Sent Event and flushes it to the client.
+// A flush error means the client disconnected.
+func writeSSE(w *bufio.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func toQueryOptions(req dto.QueryDocumentRequest) document.QueryOptions {
+	// previous turns sent by the client, used to condense follow-up questions
+	var history []entity.Message
+	for _, turn := range req.History {
+		role := entity.MessageRoleUser
+		if turn.Role == string(entity.MessageRoleAssistant) {
+			role = entity.MessageRoleAssistant
+		}
+		history = append(history, entity.Message{Role: role, Content: turn.Content})
+	}
+
+	return document.QueryOptions{
+		Filter: document.QueryFilter{
+			DocumentIDs: req.DocumentIDs,
+			MimeTypes:   req.MimeTypes,
+			UploadedBy:  req.UploadedBy,
+			CreatedFrom: req.CreatedFrom,
+			CreatedTo:   req.CreatedTo,
+		},
+		History:         history,
+		CondenseQuery:   req.CondenseQuery == nil || *req.CondenseQuery,
+		SearchMode:      req.SearchMode,
+		VectorWeight:    req.VectorWeight,
+		KeywordWeight:   req.KeywordWeight,
+		MMRLambda:       req.MMRLambda,
+		NeighbourChunks: req.NeighbourChunks,
+		Expansion:       req.Expansion,
+	}
+}
+
+// validateSearch rejects unknown search modes and expansions, negative fusion
+// weights and MMR lambdas or neighbour counts out of range
+func (h *DocumentHandler) validateSearch(req dto.QueryDocumentRequest) error {
+	if req.SearchMode != "" && !h.docUsecase.SupportsSearchMode(req.SearchMode) {
+		return fmt.Errorf("%w: %s", document.ErrUnknownSearchMode, req.SearchMode)
+	}
+	if req.Expansion != "" && !h.docUsecase.SupportsExpansion(req.Expansion) {
+		return fmt.Errorf("%w: %s", document.ErrUnknownExpansion, req.Expansion)
+	}
+	if (req.VectorWeight != nil && *req.VectorWeight < 0) || (req.KeywordWeight != nil && *req.KeywordWeight < 0) {
+		return errors.New("fusion weights must not be negative")
+	}
+	if req.MMRLambda != nil && (*req.MMRLambda < 0 || *req.MMRLambda > 1) {
+		return errors.New("mmrLambda must be between 0 and 1")
+	}
+	if req.NeighbourChunks != nil && (*req.NeighbourChunks < 0 || *req.NeighbourChunks > document.MaxNeighbourChunks) {
+		return fmt.Errorf("neighbourChunks must be between 0 and %d", document.MaxNeighbourChunks)
+	}
+	return nil
+}
+
+// Convert chunks to sources
+func toChunkSources(chunks []entity.SimilarChunk) []dto.ChunkSource {
+	var sources []dto.ChunkSource
+	for _, chunk := range chunks {
+		metadata := document.ParseChunkMetadata(chunk.Metadata)
+		sources = append(sources, dto.ChunkSource{
+			DocumentID:     chunk.DocumentID,
+			Content:        chunk.Content,
+			Similarity:     chunk.Similarity,
+			Score:          chunk.Score,
+			RerankScore:    chunk.RerankScore,
+			ChunkIndex:     chunk.ChunkIndex,
+			StartPage:      metadata.StartPage,
+			EndPage:        metadata.EndPage,
+			SlideNumber:    metadata.SlideNumber,
+			EndSlideNumber: metadata.EndSlideNumber,
+			HeadingPath:    metadata.HeadingPath,
+			SheetName:      metadata.SheetName,
+			StartRow:       metadata.StartRow,
+			EndRow:         metadata.EndRow,
+		})
+	}
+	return sources
+}
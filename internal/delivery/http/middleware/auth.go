@@ -1,40 +1,55 @@
-package middleware
-
-import (
-	"strings"
-
-	"rag-api/pkg/jwt"
-
-	"github.com/gofiber/fiber/v2"
-)
-
-func JWTAuth(secret string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization header required"})
-		}
-
-		// Extract token from "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid authorization header format"})
-		}
-
-		tokenString := parts[1]
-
-		// Validate token
-		claims, err := jwt.ValidateToken(tokenString, secret)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
-		}
-
-		// Set user info to context
-		c.Locals("userID", claims.UserID)
-		c.Locals("email", claims.Email)
-		c.Locals("role", claims.Role)
-		c.Locals("major", claims.Major)
-
-		return c.Next()
-	}
-}
+package middleware
+
+import (
+	"strings"
+
+	"rag-api/internal/domain/entity"
+
+	"rag-api/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func JWTAuth(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization header required"})
+		}
+
+		// Extract token from "Bearer <token>"
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid authorization header format"})
+		}
+
+		tokenString := parts[1]
+
+		// Validate token
+		claims, err := jwt.ValidateToken(tokenString, secret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		}
+
+		// Set user info to context
+		c.Locals("userID", claims.UserID)
+		c.Locals("email", claims.Email)
+		c.Locals("role", claims.Role)
+		c.Locals("major", claims.Major)
+
+		return c.Next()
+	}
+}
+
+// RequireRole only lets users with one of the roles through, it must run after JWTAuth
+func RequireRole(roles ...entity.UserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, allowed := range roles {
+			if strings.EqualFold(role, string(allowed)) {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+	}
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+type MessageRole string
+
+const (
+	MessageRoleUser      MessageRole = "USER"
+	MessageRoleAssistant MessageRole = "ASSISTANT"
+)
+
+type Conversation struct {
+	ID        string    `db:"id" json:"id"`
+	UserID    string    `db:"userId" json:"userId"`
+	Title     string    `db:"title" json:"title"`
+	CreatedAt time.Time `db:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `db:"updatedAt" json:"updatedAt"`
+}
+
+// MessageSource is a chunk cited by an assistant message
+type MessageSource struct {
+	ChunkID        string   `json:"chunkId"`
+	DocumentID     string   `json:"documentId"`
+	ChunkIndex     int      `json:"chunkIndex"`
+	Similarity     float64  `json:"similarity"`
+	StartPage      int      `json:"startPage,omitempty"`
+	EndPage        int      `json:"endPage,omitempty"`
+	SlideNumber    int      `json:"slideNumber,omitempty"`
+	EndSlideNumber int      `json:"endSlideNumber,omitempty"`
+	HeadingPath    []string `json:"headingPath,omitempty"`
+	SheetName      string   `json:"sheetName,omitempty"`
+	StartRow       int      `json:"startRow,omitempty"`
+	EndRow         int      `json:"endRow,omitempty"`
+}
+
+type Message struct {
+	ID             string      `db:"id" json:"id"`
+	ConversationID string      `db:"conversationId" json:"conversationId"`
+	Role           MessageRole `db:"role" json:"role"`
+	Content        string      `db:"content" json:"content"`
+	Sources        []byte      `db:"sources" json:"sources"`
+	// Sequence orders the messages of a conversation, turns written within
+	// the same millisecond included
+	Sequence  int64     `db:"sequence" json:"-"`
+	CreatedAt time.Time `db:"createdAt" json:"createdAt"`
+}
@@ -1,28 +1,45 @@
-package entity
-
-import (
-	"time"
-
-	"github.com/pgvector/pgvector-go"
-)
-
-type ChunkMetadata struct {
-	Source     string  `json:"source"` // "text" or "ocr"
-	PageNumber int     `json:"pageNumber,omitempty"`
-	Confidence float64 `json:"confidence,omitempty"`
-}
-
-type DocumentChunk struct {
-	ID         string          `db:"id" json:"id"`
-	DocumentID string          `db:"documentId" json:"documentId"`
-	ChunkIndex int             `db:"chunkIndex" json:"chunkIndex"`
-	Content    string          `db:"content" json:"content"`
-	Embedding  pgvector.Vector `db:"embedding" json:"-"`
-	Metadata   []byte          `db:"metadata" json:"metadata"`
-	CreatedAt  time.Time       `db:"createdAt" json:"createdAt"`
-}
-
-type SimilarChunk struct {
-	DocumentChunk
-	Similarity float64 `db:"similarity" json:"similarity"`
-}
+package entity
+
+import (
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+type ChunkMetadata struct {
+	Source     string `json:"source"` // "text" or "ocr"
+	PageNumber int    `json:"pageNumber,omitempty"`
+	// pages the chunk spans, a chunk can run over a page break
+	StartPage int `json:"startPage,omitempty"`
+	EndPage   int `json:"endPage,omitempty"`
+	// slides the chunk spans, for presentations
+	SlideNumber    int `json:"slideNumber,omitempty"`
+	EndSlideNumber int `json:"endSlideNumber,omitempty"`
+	// headings the chunk starts under, outermost first
+	HeadingPath []string `json:"headingPath,omitempty"`
+	// sheet and spreadsheet rows the chunk covers, for tables
+	SheetName  string  `json:"sheetName,omitempty"`
+	StartRow   int     `json:"startRow,omitempty"`
+	EndRow     int     `json:"endRow,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type DocumentChunk struct {
+	ID         string          `db:"id" json:"id"`
+	DocumentID string          `db:"documentId" json:"documentId"`
+	ChunkIndex int             `db:"chunkIndex" json:"chunkIndex"`
+	Content    string          `db:"content" json:"content"`
+	Embedding  pgvector.Vector `db:"embedding" json:"-"`
+	Metadata   []byte          `db:"metadata" json:"metadata"`
+	CreatedAt  time.Time       `db:"createdAt" json:"createdAt"`
+}
+
+type SimilarChunk struct {
+	DocumentChunk
+	Similarity float64 `db:"similarity" json:"similarity"`
+	// Score is the fused rank of a hybrid search or of the searches for the
+	// expansions of a query, zero for a single vector search
+	Score float64 `db:"score" json:"score,omitempty"`
+	// RerankScore is set when a reranker ordered the chunks
+	RerankScore *float64 `db:"-" json:"rerankScore,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "PENDING"
+	JobRunning   JobStatus = "RUNNING"
+	JobCompleted JobStatus = "COMPLETED"
+	JobFailed    JobStatus = "FAILED"
+)
+
+// IngestionJob is a queued request to process an uploaded document
+type IngestionJob struct {
+	ID         string     `db:"id" json:"id"`
+	DocumentID string     `db:"documentId" json:"documentId"`
+	Status     JobStatus  `db:"status" json:"status"`
+	Attempts   int        `db:"attempts" json:"attempts"`
+	LastError  *string    `db:"lastError" json:"lastError,omitempty"`
+	MimeType   string     `db:"mimeType" json:"mimeType"`
+	RunAt      time.Time  `db:"runAt" json:"runAt"`
+	LockedAt   *time.Time `db:"lockedAt" json:"lockedAt,omitempty"`
+	CreatedAt  time.Time  `db:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,8 @@
+package entity
+
+// TokenUsage is the number of tokens an LLM call consumed
+type TokenUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
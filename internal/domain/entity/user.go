@@ -1,24 +1,24 @@
-package entity
-
-import "time"
-
-type UserRole string
-
-const (
-	RoleStudent UserRole = "student"
-	RoleTeacher UserRole = "teacher"
-	RoleAdmin   UserRole = "admin"
-)
-
-type User struct {
-	ID        string    `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Password  string    `db:"password" json:"-"`
-	Name      string    `db:"name" json:"name"`
-	Major     string    `db:"major" json:"major"`
-	Role      UserRole  `db:"role" json:"role"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
-}
-
-
+package entity
+
+import "time"
+
+type UserRole string
+
+const (
+	RoleStudent UserRole = "student"
+	RoleTeacher UserRole = "teacher"
+	RoleAdmin   UserRole = "admin"
+)
+
+type User struct {
+	ID        string    `db:"id" json:"id"`
+	Email     string    `db:"email" json:"email"`
+	Password  string    `db:"password" json:"-"`
+	Name      string    `db:"name" json:"name"`
+	Major     string    `db:"major" json:"major"`
+	Role      UserRole  `db:"role" json:"role"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+
@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// BlobStore keeps the original uploaded files, addressed by key
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns nil when there is no blob for the key
+	Get(ctx context.Context, key string) ([]byte, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete is a no-op when there is no blob for the key
+	Delete(ctx context.Context, key string) error
+}
@@ -1,15 +1,60 @@
-package repository
-
-import (
-	"context"
-	"rag-api/internal/domain/entity"
-
-	"github.com/pgvector/pgvector-go"
-)
-
-type ChunkRepository interface {
-	Create(ctx context.Context, chunk *entity.DocumentChunk) error
-	CreateBatch(ctx context.Context, chunks []entity.DocumentChunk) error
-	SearchSimilar(ctx context.Context, embedding pgvector.Vector, topK int, threshold float64) ([]entity.SimilarChunk, error)
-	DeleteByDocumentID(ctx context.Context, documentID string) error
-}
+package repository
+
+import (
+	"context"
+	"rag-api/internal/domain/entity"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ChunkSearchFilter narrows a similarity search. UserID is always applied:
+// only chunks from the user's own documents or PUBLIC documents are returned.
+// Empty optional fields are ignored.
+type ChunkSearchFilter struct {
+	UserID    string
+	TopK      int
+	Threshold float64
+
+	DocumentIDs []string
+	MimeTypes   []string
+	UploadedBy  string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// HybridSearch ranks chunks both by vector similarity and by a full-text
+// match on Query, then fuses the two rankings with reciprocal rank fusion: a
+// chunk scores VectorWeight/(RRFK+vector rank) + KeywordWeight/(RRFK+keyword
+// rank). The threshold only applies to the vector ranking, so exact keyword
+// matches are found even when their embeddings are not similar.
+type HybridSearch struct {
+	Query         string
+	VectorWeight  float64
+	KeywordWeight float64
+	RRFK          int
+	// Candidates is how many chunks of each ranking are fused
+	Candidates int
+}
+
+// ChunkRange selects the chunks of a document with From <= chunkIndex <= To
+type ChunkRange struct {
+	DocumentID string
+	From       int
+	To         int
+}
+
+type ChunkRepository interface {
+	Create(ctx context.Context, chunk *entity.DocumentChunk) error
+	CreateBatch(ctx context.Context, chunks []entity.DocumentChunk) error
+	// ReplaceByDocumentID atomically replaces all chunks of a document
+	ReplaceByDocumentID(ctx context.Context, documentID string, chunks []entity.DocumentChunk) error
+	// SearchSimilar and SearchHybrid return the chunks with their embeddings
+	SearchSimilar(ctx context.Context, embedding pgvector.Vector, filter ChunkSearchFilter) ([]entity.SimilarChunk, error)
+	SearchHybrid(ctx context.Context, embedding pgvector.Vector, search HybridSearch, filter ChunkSearchFilter) ([]entity.SimilarChunk, error)
+	// ListByRanges returns the chunks within any of the ranges, ordered by
+	// document and chunk index. Like a search it only returns chunks of
+	// completed documents that are public or owned by the user.
+	ListByRanges(ctx context.Context, userID string, ranges []ChunkRange) ([]entity.DocumentChunk, error)
+	DeleteByDocumentID(ctx context.Context, documentID string) error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"rag-api/internal/domain/entity"
+	"time"
+)
+
+// ErrJobNotClaimed is returned for a job that is no longer RUNNING under the
+// claim it was updated with, e.g. because it went stale and was recovered
+var ErrJobNotClaimed = errors.New("ingestion job is no longer claimed")
+
+// JobRepository is the ingestion queue. A claim is identified by the attempt
+// it counted, so Heartbeat, Complete, Retry and Fail only apply to a job that
+// is still RUNNING with the attempts of the claimed copy.
+type JobRepository interface {
+	Enqueue(ctx context.Context, job *entity.IngestionJob) error
+	// EnqueueIfIdle enqueues the job unless the document already has a
+	// PENDING or RUNNING one, in a single step. It reports whether it did.
+	EnqueueIfIdle(ctx context.Context, job *entity.IngestionJob) (bool, error)
+	// Claim locks the oldest due PENDING job, marks it RUNNING and counts the
+	// attempt. It returns nil when there is nothing to do.
+	Claim(ctx context.Context) (*entity.IngestionJob, error)
+	// Heartbeat refreshes the lock of a running job, so it doesn't go stale
+	Heartbeat(ctx context.Context, job *entity.IngestionJob) error
+	Complete(ctx context.Context, job *entity.IngestionJob) error
+	// Retry puts the job back to PENDING, due after delay
+	Retry(ctx context.Context, job *entity.IngestionJob, delay time.Duration, lastError string) error
+	Fail(ctx context.Context, job *entity.IngestionJob, lastError string) error
+	// RecoverStale puts RUNNING jobs locked longer than staleAfter back to PENDING
+	RecoverStale(ctx context.Context, staleAfter time.Duration) (int, error)
+}
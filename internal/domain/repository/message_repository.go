@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"rag-api/internal/domain/entity"
+)
+
+type MessageRepository interface {
+	Create(ctx context.Context, msg *entity.Message) error
+	// CreateBatch stores the messages in order, all or none of them
+	CreateBatch(ctx context.Context, msgs []entity.Message) error
+	ListByConversation(ctx context.Context, conversationID string) ([]entity.Message, error)
+	ListRecentByConversation(ctx context.Context, conversationID string, limit int) ([]entity.Message, error)
+}
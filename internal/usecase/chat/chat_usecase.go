@@ -0,0 +1,258 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+	"rag-api/internal/usecase/document"
+)
+
+// defaultTitle is replaced by the first question asked in the conversation
+const defaultTitle = "New conversation"
+
+var ErrConversationNotFound = errors.New("conversation not found")
+
+type Retriever interface {
+	RetrieveChunks(ctx context.Context, userID, query string, opts document.QueryOptions) (*document.RetrievalResult, error)
+}
+
+type ChatService interface {
+	GenerateAnswerWithHistory(ctx context.Context, query, context string, history []entity.Message) (string, error)
+}
+
+type ChatUsecase struct {
+	convRepo     repository.ConversationRepository
+	msgRepo      repository.MessageRepository
+	retriever    Retriever
+	chatService  ChatService
+	historyLimit int
+}
+
+func NewChatUsecase(
+	convRepo repository.ConversationRepository,
+	msgRepo repository.MessageRepository,
+	retriever Retriever,
+	chatService ChatService,
+	historyLimit int,
+) *ChatUsecase {
+	return &ChatUsecase{
+		convRepo:     convRepo,
+		msgRepo:      msgRepo,
+		retriever:    retriever,
+		chatService:  chatService,
+		historyLimit: historyLimit,
+	}
+}
+
+// create conversation
+func (uc *ChatUsecase) CreateConversation(
+	ctx context.Context,
+	userID string,
+	title string,
+) (*entity.Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = defaultTitle
+	}
+
+	conv := &entity.Conversation{
+		UserID: userID,
+		Title:  title,
+	}
+	if err := uc.convRepo.Create(ctx, conv); err != nil {
+		return nil, err
+	}
+
+	return conv, nil
+}
+
+// list conversation
+func (uc *ChatUsecase) ListConversations(
+	ctx context.Context,
+	userID string,
+	page, limit int,
+) ([]entity.Conversation, int, error) {
+	return uc.convRepo.List(ctx, userID, page, limit)
+}
+
+// get conversation with all of its messages
+func (uc *ChatUsecase) GetConversation(
+	ctx context.Context,
+	conversationID string,
+	userID string,
+) (*entity.Conversation, []entity.Message, error) {
+	conv, err := uc.findConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messages, err := uc.msgRepo.ListByConversation(ctx, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conv, messages, nil
+}
+
+// rename conversation
+func (uc *ChatUsecase) RenameConversation(
+	ctx context.Context,
+	conversationID string,
+	userID string,
+	title string,
+) (*entity.Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	conv, err := uc.findConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	conv.Title = title
+	if err := uc.convRepo.Update(ctx, conv); err != nil {
+		return nil, err
+	}
+
+	return conv, nil
+}
+
+// delete conversation
+func (uc *ChatUsecase) DeleteConversation(
+	ctx context.Context,
+	conversationID string,
+	userID string,
+) error {
+	if _, err := uc.findConversation(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	return uc.convRepo.Delete(ctx, conversationID)
+}
+
+// send message, runs retrieval with the recent history and stores both turns
+// once the answer is generated.
+// With condenseQuery set, a follow-up is rewritten into a standalone query
+// before retrieval.
+func (uc *ChatUsecase) SendMessage(
+	ctx context.Context,
+	conversationID string,
+	userID string,
+	content string,
+	condenseQuery bool,
+) (*entity.Message, *entity.Message, *document.RetrievalResult, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, nil, nil, errors.New("message is required")
+	}
+
+	conv, err := uc.findConversation(ctx, conversationID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 1. load the last N turns
+	history, err := uc.msgRepo.ListRecentByConversation(ctx, conversationID, uc.historyLimit)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	firstTurn := len(history) == 0
+	// a limit cutting a turn in half leaves its answer first
+	if len(history) > 0 && history[0].Role == entity.MessageRoleAssistant {
+		history = history[1:]
+	}
+
+	// 2. retrieve chunks and generate answer
+	retrieval, err := uc.retriever.RetrieveChunks(ctx, userID, content, document.QueryOptions{
+		History:       history,
+		CondenseQuery: condenseQuery,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	chunks := retrieval.Chunks
+
+	answer := document.NoRelevantInfoAnswer
+	if len(chunks) > 0 {
+		answer, err = uc.chatService.GenerateAnswerWithHistory(ctx, content, document.BuildContext(chunks), history)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to generate answer: %w", err)
+		}
+	}
+
+	// 3. save both turns together only once the answer exists, so a failed
+	// retrieval, generation or insert leaves no unanswered question behind
+	sources := make([]entity.MessageSource, 0, len(chunks))
+	for _, chunk := range chunks {
+		metadata := document.ParseChunkMetadata(chunk.Metadata)
+		sources = append(sources, entity.MessageSource{
+			ChunkID:        chunk.ID,
+			DocumentID:     chunk.DocumentID,
+			ChunkIndex:     chunk.ChunkIndex,
+			Similarity:     chunk.Similarity,
+			StartPage:      metadata.StartPage,
+			EndPage:        metadata.EndPage,
+			SlideNumber:    metadata.SlideNumber,
+			EndSlideNumber: metadata.EndSlideNumber,
+			HeadingPath:    metadata.HeadingPath,
+			SheetName:      metadata.SheetName,
+			StartRow:       metadata.StartRow,
+			EndRow:         metadata.EndRow,
+		})
+	}
+	sourcesJSON, _ := json.Marshal(sources)
+
+	turn := []entity.Message{
+		{
+			ConversationID: conversationID,
+			Role:           entity.MessageRoleUser,
+			Content:        content,
+		},
+		{
+			ConversationID: conversationID,
+			Role:           entity.MessageRoleAssistant,
+			Content:        answer,
+			Sources:        sourcesJSON,
+		},
+	}
+	if err := uc.msgRepo.CreateBatch(ctx, turn); err != nil {
+		return nil, nil, nil, err
+	}
+	userMsg, assistantMsg := &turn[0], &turn[1]
+
+	// 4. title untitled conversations after their first question, and bump updatedAt
+	if firstTurn && conv.Title == defaultTitle {
+		conv.Title = generateTitle(content)
+	}
+	if err := uc.convRepo.Update(ctx, conv); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return userMsg, assistantMsg, retrieval, nil
+}
+
+func (uc *ChatUsecase) findConversation(ctx context.Context, conversationID, userID string) (*entity.Conversation, error) {
+	conv, err := uc.convRepo.FindByIDAndUserID(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		return nil, ErrConversationNotFound
+	}
+	return conv, nil
+}
+
+func generateTitle(message string) string {
+	if utf8.RuneCountInString(message) > 50 {
+		return string([]rune(message)[:50]) + "..."
+	}
+	return message
+}
@@ -0,0 +1,103 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rag-api/internal/adapter/repository/memory"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/document"
+)
+
+type stubRetriever struct {
+	err error
+	// history receives the history of every retrieval when set
+	history *[]entity.Message
+}
+
+func (r stubRetriever) RetrieveChunks(ctx context.Context, userID, query string, opts document.QueryOptions) (*document.RetrievalResult, error) {
+	if r.history != nil {
+		*r.history = opts.History
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &document.RetrievalResult{SearchQuery: query}, nil
+}
+
+type stubChatService struct{}
+
+func (stubChatService) GenerateAnswerWithHistory(ctx context.Context, query, context string, history []entity.Message) (string, error) {
+	return "answer", nil
+}
+
+func newTestUsecase(t *testing.T, retriever Retriever, historyLimit int) (*ChatUsecase, *entity.Conversation) {
+	t.Helper()
+	ctx := context.Background()
+	db := memory.NewDB()
+
+	user := &entity.User{Email: "budi@kampus.ac.id", Password: "hashed", Name: "Budi", Major: "Informatika", Role: entity.RoleStudent}
+	if err := memory.NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	uc := NewChatUsecase(memory.NewConversationRepository(db), memory.NewMessageRepository(db), retriever, stubChatService{}, historyLimit)
+	conv, err := uc.CreateConversation(ctx, user.ID, "")
+	if err != nil {
+		t.Fatalf("create conversation: %v", err)
+	}
+	return uc, conv
+}
+
+func TestSendMessageStoresBothTurns(t *testing.T) {
+	uc, conv := newTestUsecase(t, stubRetriever{}, 10)
+
+	if _, _, _, err := uc.SendMessage(context.Background(), conv.ID, conv.UserID, "Apa itu graf?", false); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	_, messages, err := uc.GetConversation(context.Background(), conv.ID, conv.UserID)
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Role != entity.MessageRoleUser || messages[1].Role != entity.MessageRoleAssistant {
+		t.Fatalf("got messages %+v, want the question followed by the answer", messages)
+	}
+	if messages[1].Content != document.NoRelevantInfoAnswer {
+		t.Errorf("got answer %q without chunks, want %q", messages[1].Content, document.NoRelevantInfoAnswer)
+	}
+}
+
+func TestSendMessageFailureStoresNothing(t *testing.T) {
+	retrievalErr := errors.New("embedding provider down")
+	uc, conv := newTestUsecase(t, stubRetriever{err: retrievalErr}, 10)
+
+	if _, _, _, err := uc.SendMessage(context.Background(), conv.ID, conv.UserID, "Apa itu graf?", false); !errors.Is(err, retrievalErr) {
+		t.Fatalf("SendMessage returned %v, want %v", err, retrievalErr)
+	}
+
+	_, messages, err := uc.GetConversation(context.Background(), conv.ID, conv.UserID)
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("got %d messages after a failed retrieval, want none", len(messages))
+	}
+}
+
+func TestSendMessageHistoryStartsWithAQuestion(t *testing.T) {
+	var history []entity.Message
+	uc, conv := newTestUsecase(t, stubRetriever{history: &history}, 3)
+
+	for _, question := range []string{"Apa itu graf?", "Apa itu pohon?", "Apa bedanya?"} {
+		if _, _, _, err := uc.SendMessage(context.Background(), conv.ID, conv.UserID, question, false); err != nil {
+			t.Fatalf("SendMessage: %v", err)
+		}
+	}
+
+	// the last 3 messages start with the first answer, which is dropped
+	if len(history) != 2 || history[0].Content != "Apa itu pohon?" || history[1].Role != entity.MessageRoleAssistant {
+		t.Errorf("got history %+v, want the second question and its answer", history)
+	}
+}
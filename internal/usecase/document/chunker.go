@@ -1,90 +1,344 @@
-package document
-
-import (
-	"strings"
-	"unicode"
-)
-
-type Chunker struct {
-	chunkSize    int
-	chunkOverlap int
-}
-
-// NewChunker creates a new chunker
-func NewChunker(chunkSize, chunkOverlap int) *Chunker {
-	return &Chunker{
-		chunkSize:    chunkSize,
-		chunkOverlap: chunkOverlap,
-	}
-}
-
-func (c *Chunker) ChunkText(text string) []string {
-	//clean text
-	text = strings.TrimSpace(text)
-	text = cleanText(text)
-
-	if len(text) == 0 {
-		return []string{}
-	}
-
-	var chunks []string
-	start := 0
-
-	for start < len(text) {
-		end := start + c.chunkSize
-		if end > len(text) {
-			end = len(text)
-		}
-
-		// try to break at sentence boundary
-		if end < len(text) {
-			for i := end; i > start+c.chunkSize/2; i-- {
-				if text[i] == '.' || text[i] == '!' || text[i] == '?' || text[i] == '\n' {
-					end = i + 1
-					break
-				}
-			}
-		}
-		chunk := strings.TrimSpace(text[start:end])
-		if len(chunk) > 0 {
-			chunks = append(chunks, chunk)
-		}
-
-		// move start position with overlap
-		// move start position with overlap
-		newStart := end - c.chunkOverlap
-		if newStart <= start {
-			// ensure progress to avoid infinite loop
-			newStart = start + 1
-		}
-		start = newStart
-
-		// sanity check
-		if start >= len(text) {
-			break
-		}
-	}
-
-	return chunks
-}
-
-func cleanText(text string) string {
-	// remove multiple whitespace
-	var result strings.Builder
-	prevSpace := false
-
-	for _, r := range text {
-		if unicode.IsSpace(r) {
-			if !prevSpace {
-				result.WriteRune(' ')
-				prevSpace = true
-			}
-		} else {
-			result.WriteRune(r)
-			prevSpace = false
-		}
-
-	}
-
-	return result.String()
-}
+package document
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts tokens the way the embedding model does
+type Tokenizer interface {
+	Count(text string) int
+	// Offsets returns the byte offset at which every token of text starts
+	Offsets(text string) []int
+}
+
+// Chunker splits text into chunks measured in tokens of the embedding model
+type Chunker struct {
+	tokenizer    Tokenizer
+	chunkSize    int
+	chunkOverlap int
+	maxTokens    int
+}
+
+// NewChunker creates a new chunker. chunkSize and chunkOverlap are in tokens;
+// no chunk is ever longer than maxTokens, the input limit of the embedding
+// model.
+func NewChunker(tokenizer Tokenizer, chunkSize, chunkOverlap, maxTokens int) *Chunker {
+	if chunkSize > maxTokens {
+		chunkSize = maxTokens
+	}
+	return &Chunker{
+		tokenizer:    tokenizer,
+		chunkSize:    chunkSize,
+		chunkOverlap: chunkOverlap,
+		maxTokens:    maxTokens,
+	}
+}
+
+// TextChunk is a chunk of text with the segments it starts and ends in
+type TextChunk struct {
+	Content string
+	First   Segment
+	Last    Segment
+	// StartRow and EndRow are the sheet rows of a table chunk
+	StartRow int
+	EndRow   int
+	// OCR is set when any of the text was recognized from an image, with the
+	// lowest confidence of those segments
+	OCR        bool
+	Confidence float64
+}
+
+// ChunkSegments chunks consecutive text segments as one text, so a chunk can
+// run over a page break, and records the first and last segment of every
+// chunk. Tables are chunked by rows on their own.
+func (c *Chunker) ChunkSegments(segments []Segment) []TextChunk {
+	chunks := []TextChunk{}
+	start := 0
+	for i, segment := range segments {
+		if segment.Table == nil {
+			continue
+		}
+		chunks = append(chunks, c.chunkText(segments[start:i])...)
+		chunks = append(chunks, c.chunkTable(segment)...)
+		start = i + 1
+	}
+	return append(chunks, c.chunkText(segments[start:])...)
+}
+
+func (c *Chunker) chunkText(segments []Segment) []TextChunk {
+	joined := joinSegments(segments, cleanText, " ")
+
+	var chunks []TextChunk
+	for _, s := range c.split(joined.text) {
+		chunks = append(chunks, joined.chunk(s))
+	}
+	return chunks
+}
+
+// joinedText is the text of consecutive segments joined into one, so a chunk
+// can span several of them
+type joinedText struct {
+	text string
+	// offsets holds where each of segments starts in text
+	offsets  []int
+	segments []Segment
+}
+
+// joinSegments cleans the text of every segment and joins the non-empty ones
+// with separator
+func joinSegments(segments []Segment, clean func(string) string, separator string) joinedText {
+	var builder strings.Builder
+	var joined joinedText
+	for _, segment := range segments {
+		text := clean(strings.TrimSpace(segment.Text))
+		if len(text) == 0 {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString(separator)
+		}
+		joined.offsets = append(joined.offsets, builder.Len())
+		joined.segments = append(joined.segments, segment)
+		builder.WriteString(text)
+	}
+	joined.text = builder.String()
+	return joined
+}
+
+// chunk returns the chunk of a span of the text, with the segments it starts
+// and ends in
+func (j joinedText) chunk(s span) TextChunk {
+	// segmentAt finds the index of the segment containing the byte at pos
+	segmentAt := func(pos int) int {
+		return sort.Search(len(j.offsets), func(i int) bool { return j.offsets[i] > pos }) - 1
+	}
+
+	first, last := segmentAt(s.start), segmentAt(s.end-1)
+	chunk := TextChunk{
+		Content: j.text[s.start:s.end],
+		First:   j.segments[first],
+		Last:    j.segments[last],
+	}
+	for _, segment := range j.segments[first : last+1] {
+		if segment.OCR && (!chunk.OCR || segment.Confidence < chunk.Confidence) {
+			chunk.OCR = true
+			chunk.Confidence = segment.Confidence
+		}
+	}
+	return chunk
+}
+
+// limit returns the chunk of a span merged from pieces, whose token counts
+// only add up roughly. A chunk over maxTokens is cut into token windows.
+func (c *Chunker) limit(joined joinedText, s span) []TextChunk {
+	if c.tokenizer.Count(joined.text[s.start:s.end]) <= c.maxTokens {
+		return []TextChunk{joined.chunk(s)}
+	}
+
+	var chunks []TextChunk
+	for _, part := range c.split(joined.text[s.start:s.end]) {
+		chunks = append(chunks, joined.chunk(span{start: s.start + part.start, end: s.start + part.end}))
+	}
+	return chunks
+}
+
+// chunkTable groups whole rows into chunks of up to chunkSize, keeping the
+// line structure. Every chunk starts with the sheet name and the header row so
+// it can be understood on its own.
+func (c *Chunker) chunkTable(segment Segment) []TextChunk {
+	table := segment.Table
+
+	var prefix strings.Builder
+	if segment.SheetName != "" {
+		prefix.WriteString("Sheet: " + segment.SheetName + "\n")
+	}
+	if len(table.Header) > 0 {
+		prefix.WriteString(tableRow(table.Header) + "\n")
+	}
+
+	var chunks []TextChunk
+	var body strings.Builder
+	first := 0
+	flush := func(end int) {
+		if body.Len() == 0 {
+			return
+		}
+		content := strings.TrimSpace(prefix.String() + body.String())
+		// the token counts of the lines only add up roughly, and a single
+		// row can be longer than the model accepts
+		parts := []string{content}
+		if c.tokenizer.Count(content) > c.maxTokens {
+			parts = nil
+			for _, s := range c.split(content) {
+				parts = append(parts, content[s.start:s.end])
+			}
+		}
+		for _, part := range parts {
+			chunks = append(chunks, TextChunk{
+				Content:  part,
+				First:    segment,
+				Last:     segment,
+				StartRow: table.RowNumbers[first],
+				EndRow:   table.RowNumbers[end],
+			})
+		}
+		body.Reset()
+	}
+
+	prefixTokens, bodyTokens := c.tokenizer.Count(prefix.String()), 0
+	for i, row := range table.Rows {
+		line := tableRow(row) + "\n"
+		lineTokens := c.tokenizer.Count(line)
+		// a row that doesn't fit starts the next chunk, a single huge row
+		// still gets a chunk of its own
+		if body.Len() > 0 && prefixTokens+bodyTokens+lineTokens > c.chunkSize {
+			flush(i - 1)
+			first, bodyTokens = i, 0
+		}
+		body.WriteString(line)
+		bodyTokens += lineTokens
+	}
+	flush(len(table.Rows) - 1)
+
+	return chunks
+}
+
+func tableRow(cells []string) string {
+	cleaned := make([]string, len(cells))
+	for i, cell := range cells {
+		cleaned[i] = cleanText(strings.TrimSpace(cell))
+	}
+	return strings.Join(cleaned, " | ")
+}
+
+type span struct {
+	start, end int
+}
+
+// split returns the byte ranges of the chunks of a text, trimmed of
+// surrounding spaces. A chunk holds up to chunkSize tokens, overlaps the
+// previous one by chunkOverlap tokens and never ends inside a rune.
+func (c *Chunker) split(text string) []span {
+	if len(text) == 0 {
+		return nil
+	}
+
+	offsets := c.tokenizer.Offsets(text)
+	// tokenStart is the byte offset of token i, the end of the text past the
+	// last token
+	tokenStart := func(i int) int {
+		if i >= len(offsets) {
+			return len(text)
+		}
+		return offsets[i]
+	}
+
+	var spans []span
+	start := 0
+
+	for start < len(offsets) {
+		startByte := runeStart(text, tokenStart(start))
+		end := tokenStart(start + c.chunkSize)
+
+		if end < len(text) {
+			// try to break at sentence boundary
+			half := tokenStart(start + c.chunkSize/2)
+			for i := end - 1; i > half; i-- {
+				if text[i] == '.' || text[i] == '!' || text[i] == '?' || text[i] == '\n' {
+					end = i + 1
+					break
+				}
+			}
+
+			// a token can end inside a multi-byte rune
+			if boundary := runeStart(text, end); boundary > startByte {
+				end = boundary
+			} else {
+				end = nextRuneStart(text, end)
+			}
+		}
+
+		// trim spaces, cleanText leaves no other whitespace
+		s, e := startByte, end
+		for s < e && text[s] == ' ' {
+			s++
+		}
+		e = trimRight(text, s, e)
+
+		// the chunk on its own can tokenize to a few more tokens than the
+		// window it was cut from, drop runes until it fits but keep one
+		if c.tokenizer.Count(text[s:e]) > c.chunkSize {
+			for runeStart(text, e-1) > s && c.tokenizer.Count(text[s:e]) > c.chunkSize {
+				e = trimRight(text, s, runeStart(text, e-1))
+			}
+			end = e
+		}
+
+		if e > s {
+			spans = append(spans, span{start: s, end: e})
+		}
+
+		// the rest of the text is inside the overlap of this chunk
+		if end >= len(text) {
+			break
+		}
+
+		// move start position with overlap
+		newStart := sort.SearchInts(offsets, end) - c.chunkOverlap
+		if newStart <= start {
+			// ensure progress to avoid infinite loop
+			newStart = start + 1
+		}
+		start = newStart
+	}
+
+	return spans
+}
+
+// trimRight moves end back over the spaces before it
+func trimRight(text string, start, end int) int {
+	for end > start && text[end-1] == ' ' {
+		end--
+	}
+	return end
+}
+
+// runeStart moves pos back to the start of the rune it is in
+func runeStart(text string, pos int) int {
+	for pos > 0 && pos < len(text) && !utf8.RuneStart(text[pos]) {
+		pos--
+	}
+	return pos
+}
+
+// nextRuneStart moves pos forward to the start of the next rune
+func nextRuneStart(text string, pos int) int {
+	for pos < len(text) && !utf8.RuneStart(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func cleanText(text string) string {
+	// remove multiple whitespace
+	var result strings.Builder
+	prevSpace := false
+
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			if !prevSpace {
+				result.WriteRune(' ')
+				prevSpace = true
+			}
+		} else {
+			result.WriteRune(r)
+			prevSpace = false
+		}
+
+	}
+
+	return result.String()
+}
@@ -0,0 +1,56 @@
+package document_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/tokenizer"
+)
+
+func TestChunkerKeepsChunksWithinChunkSize(t *testing.T) {
+	bpe, err := tokenizer.ForModel("text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("tokenizer: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"prose", strings.Repeat("Graf terdiri dari simpul dan sisi yang menghubungkan simpul. ", 20)},
+		{"no sentence breaks", strings.Repeat("simpul sisi lintasan derajat ", 40)},
+		// rare CJK characters and emoji take several tokens each, which can
+		// start inside a rune
+		{"rare CJK", strings.Repeat("龘靐齉爩 ", 60)},
+		{"emoji", strings.Repeat("graf 🦀🧩 sisi 🪐 ", 40)},
+		{"accents", strings.Repeat("Ångström naïve café façade ", 30)},
+	}
+
+	for _, chunkSize := range []int{7, 16, 50} {
+		chunker := document.NewChunker(bpe, chunkSize, chunkSize/4, 8191)
+		for _, test := range tests {
+			chunks := chunker.ChunkSegments([]document.Segment{{Text: test.text, PageNumber: 1}})
+			if len(chunks) < 2 {
+				t.Errorf("%s, chunk size %d: got %d chunks, want the text split", test.name, chunkSize, len(chunks))
+			}
+
+			text := strings.TrimSpace(test.text)
+			for i, chunk := range chunks {
+				if !utf8.ValidString(chunk.Content) {
+					t.Errorf("%s, chunk size %d: chunk %d %q splits a rune", test.name, chunkSize, i, chunk.Content)
+				}
+				if tokens := bpe.Count(chunk.Content); tokens > chunkSize {
+					t.Errorf("%s, chunk size %d: chunk %d %q has %d tokens", test.name, chunkSize, i, chunk.Content, tokens)
+				}
+				if !strings.Contains(text, chunk.Content) {
+					t.Errorf("%s, chunk size %d: chunk %d %q is not part of the text", test.name, chunkSize, i, chunk.Content)
+				}
+			}
+			if !strings.HasPrefix(text, chunks[0].Content) || !strings.HasSuffix(text, chunks[len(chunks)-1].Content) {
+				t.Errorf("%s, chunk size %d: the chunks don't cover the text from start to end", test.name, chunkSize)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"rag-api/internal/domain/entity"
+)
+
+// names of the chunking strategies
+const (
+	ChunkingFixed     = "fixed"
+	ChunkingRecursive = "recursive"
+	ChunkingSemantic  = "semantic"
+)
+
+// ChunkingConfig sizes the chunks of every strategy, in tokens of the
+// embedding model
+type ChunkingConfig struct {
+	ChunkSize    int
+	ChunkOverlap int
+	// MaxTokens is the input limit of the embedding model
+	MaxTokens int
+	// Strategy is used for uploads that don't choose one
+	Strategy string
+
+	// SemanticMinTokens keeps the semantic strategy from cutting tiny chunks
+	SemanticMinTokens int
+	// SemanticBreakpointPercentile is the percentile of the sentence
+	// distances of a section above which the semantic strategy cuts
+	SemanticBreakpointPercentile float64
+}
+
+var ErrUnknownChunkingStrategy = errors.New("unknown chunking strategy")
+
+// ChunkingStrategy splits the extracted segments of a document into chunks
+type ChunkingStrategy interface {
+	Chunk(ctx context.Context, segments []Segment) ([]TextChunk, error)
+}
+
+// Chunk cuts fixed windows of chunkSize tokens, see ChunkSegments
+func (c *Chunker) Chunk(ctx context.Context, segments []Segment) ([]TextChunk, error) {
+	return c.ChunkSegments(segments), nil
+}
+
+// ChunkingStrategies lists the names of the available chunking strategies
+func (uc *DocumentUsecase) ChunkingStrategies() []string {
+	names := make([]string, 0, len(uc.strategies))
+	for name := range uc.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SupportsChunkingStrategy reports whether name is an available strategy
+func (uc *DocumentUsecase) SupportsChunkingStrategy(name string) bool {
+	_, ok := uc.strategies[name]
+	return ok
+}
+
+// strategyFor returns the strategy chosen at upload, the configured default
+// when none was
+func (uc *DocumentUsecase) strategyFor(doc *entity.Document) (ChunkingStrategy, error) {
+	name := doc.ChunkingStrategy
+	if name == "" {
+		name = uc.defaultStrategy
+	}
+	strategy, ok := uc.strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChunkingStrategy, name)
+	}
+	return strategy, nil
+}
@@ -1,268 +1,351 @@
-package document
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-	"strings"
-	"time"
-
-	"rag-api/internal/domain/entity"
-	"rag-api/internal/domain/repository"
-
-	"github.com/pgvector/pgvector-go"
-)
-
-type ChatService interface {
-	GenerateAnswer(ctx context.Context, query, context string) (string, error)
-}
-
-type EmbeddingService interface {
-	GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error)
-}
-
-type DocumentUsecase struct {
-	docRepo     repository.DocumentRepository
-	chunkRepo   repository.ChunkRepository
-	embedder    EmbeddingService
-	chatService ChatService
-	extractor   *TextExtractor
-	chunker     *Chunker
-	topK        int
-	threshold   float64
-}
-
-func NewDocumentUsecase(
-	docRepo repository.DocumentRepository,
-	chunkRepo repository.ChunkRepository,
-	embedder EmbeddingService,
-	chatService ChatService,
-	chunkSize, chunkOverlap int,
-	topK int,
-	threshold float64,
-) *DocumentUsecase {
-	return &DocumentUsecase{
-		docRepo:     docRepo,
-		chunkRepo:   chunkRepo,
-		embedder:    embedder,
-		chatService: chatService,
-		extractor:   NewTextExtractor(),
-		chunker:     NewChunker(chunkSize, chunkOverlap),
-		topK:        topK,
-		threshold:   threshold,
-	}
-}
-
-// upload document
-func (uc *DocumentUsecase) UploadDocument(
-	ctx context.Context,
-	userID string,
-	filename string,
-	fileData []byte,
-	mimeType string,
-	visibility entity.DocumentVisibility,
-) (*entity.Document, error) {
-
-	// create document record
-	doc := &entity.Document{
-		UserID:       userID,
-		Filename:     fmt.Sprintf("%d_%d_%s", userID, time.Now().Unix(), filename),
-		OriginalName: filename,
-		FileSize:     int64(len(fileData)),
-		MimeType:     mimeType,
-		Status:       entity.StatusProcessing,
-		Visibility:   visibility,
-		TotalChunks:  0,
-	}
-
-	if err := uc.docRepo.Create(ctx, doc); err != nil {
-		return nil, err
-	}
-
-	// process document in background
-	go func() {
-		// recovery for panic in background process
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("Panic in document processing for doc %s: %v", doc.ID, r)
-				uc.docRepo.UpdateStatus(context.Background(), doc.ID, entity.StatusFailed)
-			}
-		}()
-
-		if err := uc.ProcessDocument(context.Background(), doc.ID, fileData, mimeType); err != nil {
-			log.Printf("Error processing document %s: %v", doc.ID, err)
-			uc.docRepo.UpdateStatus(context.Background(), doc.ID, entity.StatusFailed)
-		}
-	}()
-
-	return doc, nil
-
-}
-
-// process document
-func (uc DocumentUsecase) ProcessDocument(
-	ctx context.Context,
-	documentID string,
-	fileData []byte,
-	mimeType string,
-) error {
-	log.Printf("Starting processing for document %s", documentID)
-
-	// 1 extract text
-	var text string
-	var err error
-
-	if mimeType == "application/pdf" {
-		text, err = uc.extractor.ExtractFromPDF(fileData)
-		if err != nil {
-			return fmt.Errorf("failed to extract text: %w", err)
-		}
-	} else {
-		return fmt.Errorf("unsupported file type: %s", mimeType)
-	}
-
-	if len(text) == 0 {
-		return fmt.Errorf("no text extracted from document")
-	}
-	log.Printf("Extracted %d characters from document %s", len(text), documentID)
-
-	// 2 chunk text
-	textChunks := uc.chunker.ChunkText(text)
-	if len(textChunks) == 0 {
-		return fmt.Errorf("no chunks generated")
-	}
-	log.Printf("Generated %d chunks from document %s", len(textChunks), documentID)
-
-	// 3 generate embeddings
-	embeddings, err := uc.embedder.GenerateBatchEmbeddings(ctx, textChunks)
-	if err != nil {
-		return fmt.Errorf("failed to generate embeddings: %w", err)
-	}
-	log.Printf("Generated %d embeddings from document %s", len(embeddings), documentID)
-
-	// 4 create chunks with embeddings
-	var chunks []entity.DocumentChunk
-	for i, content := range textChunks {
-		metadata, _ := json.Marshal(entity.ChunkMetadata{
-			Source:     "text",
-			PageNumber: i/10 + 1,
-		})
-		chunks = append(chunks, entity.DocumentChunk{
-			DocumentID: documentID,
-			ChunkIndex: i,
-			Content:    content,
-			Embedding:  embeddings[i],
-			Metadata:   metadata,
-		})
-	}
-
-	// 5 save chunks
-	if err := uc.chunkRepo.CreateBatch(ctx, chunks); err != nil {
-		return fmt.Errorf("failed to save chunks: %w", err)
-	}
-	log.Printf("Saved %d chunks to database for document %s", len(chunks), documentID)
-
-	// 6 update document status
-	if err := uc.docRepo.UpdateTotalChunks(ctx, documentID, len(chunks)); err != nil {
-		return err
-	}
-
-	if err := uc.docRepo.UpdateStatus(ctx, documentID, entity.StatusCompleted); err != nil {
-		return err
-	}
-
-	log.Printf("Document %s processed successfully with %d chunks", documentID, len(chunks))
-	return nil
-
-}
-
-// list document
-func (uc *DocumentUsecase) ListDocuments(
-	ctx context.Context,
-	userID string,
-	page, limit int,
-) ([]entity.Document, int, error) {
-	return uc.docRepo.List(ctx, userID, page, limit)
-}
-
-// get document by id
-func (uc *DocumentUsecase) GetDocumentByID(
-	ctx context.Context,
-	documentID string,
-	userID string,
-) (*entity.Document, error) {
-	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
-	if err != nil {
-		return nil, err
-	}
-	if doc == nil {
-		return nil, nil
-	}
-
-	return doc, nil
-
-}
-
-// delete
-func (uc *DocumentUsecase) DeleteDocument(
-	ctx context.Context,
-	documentID string,
-	userID string,
-) error {
-	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
-	if err != nil {
-		return err
-	}
-	if doc == nil {
-		return fmt.Errorf("document not found")
-	}
-
-	// Delete chunks first
-	if err := uc.chunkRepo.DeleteByDocumentID(ctx, documentID); err != nil {
-		return err
-	}
-
-	return uc.docRepo.Delete(ctx, documentID)
-
-}
-
-// query document
-func (uc *DocumentUsecase) QueryDocuments(
-	ctx context.Context,
-	query string,
-) (string, []entity.SimilarChunk, error) {
-
-	// 1. generate embedding untuk query
-	queryEmbedding, err := uc.embedder.GenerateBatchEmbeddings(ctx, []string{query})
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to  generate query embedding: %w", err)
-	}
-
-	// 2. search similar chunks
-	if len(queryEmbedding) == 0 {
-		return "", nil, fmt.Errorf("no embedding generated for query")
-	}
-	chunks, err := uc.chunkRepo.SearchSimilar(ctx, queryEmbedding[0], uc.topK, uc.threshold)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to search similar chunks: %w", err)
-	}
-
-	if len(chunks) == 0 {
-		return "Maaf, saya tidak menemukan informasi yang relevan dalam dokumen", nil, nil
-	}
-
-	// 3. build context from chunks
-	var contextBuilder strings.Builder
-	for i, chunk := range chunks {
-		contextBuilder.WriteString(fmt.Sprintf("[Dokumen %d - Similarity: %.2f]\n%s\n\n", i+1, chunk.Similarity, chunk.Content))
-	}
-
-	// 4, generate answer using LLM
-	answer, err := uc.chatService.GenerateAnswer(ctx, query, contextBuilder.String())
-	if err != nil {
-		return "", chunks, fmt.Errorf("failed to generate answer: %w", err)
-	}
-
-	return answer, chunks, nil
-}
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+type ChatService interface {
+	GenerateAnswer(ctx context.Context, query, context string) (string, error)
+}
+
+// StreamingChatService is a ChatService that can also stream the answer as it
+// is generated
+type StreamingChatService interface {
+	ChatService
+	GenerateAnswerStream(ctx context.Context, query, context string, onDelta func(delta string) error) (*entity.TokenUsage, error)
+}
+
+type EmbeddingService interface {
+	GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error)
+}
+
+// QueryRewriter turns a follow-up question into a standalone search query
+type QueryRewriter interface {
+	CondenseQuery(ctx context.Context, query string, history []entity.Message) (string, error)
+}
+
+type DocumentUsecase struct {
+	docRepo     repository.DocumentRepository
+	chunkRepo   repository.ChunkRepository
+	jobRepo     repository.JobRepository
+	blobStore   repository.BlobStore
+	embedder    EmbeddingService
+	chatService ChatService
+	rewriter    QueryRewriter
+	expander    QueryExpander
+	reranker    Reranker
+	ocr         OCREngine
+	extractor   *TextExtractor
+	// strategies by name, defaultStrategy is used unless one is chosen at upload
+	strategies      map[string]ChunkingStrategy
+	defaultStrategy string
+	search          SearchConfig
+	topK            int
+	threshold       float64
+}
+
+func NewDocumentUsecase(
+	docRepo repository.DocumentRepository,
+	chunkRepo repository.ChunkRepository,
+	jobRepo repository.JobRepository,
+	blobStore repository.BlobStore,
+	embedder EmbeddingService,
+	chatService ChatService,
+	rewriter QueryRewriter,
+	expander QueryExpander,
+	reranker Reranker,
+	ocr OCREngine,
+	tokenizer Tokenizer,
+	chunking ChunkingConfig,
+	search SearchConfig,
+	topK int,
+	threshold float64,
+) *DocumentUsecase {
+	chunker := NewChunker(tokenizer, chunking.ChunkSize, chunking.ChunkOverlap, chunking.MaxTokens)
+
+	return &DocumentUsecase{
+		docRepo:     docRepo,
+		chunkRepo:   chunkRepo,
+		jobRepo:     jobRepo,
+		blobStore:   blobStore,
+		embedder:    embedder,
+		chatService: chatService,
+		rewriter:    rewriter,
+		expander:    expander,
+		reranker:    reranker,
+		ocr:         ocr,
+		extractor:   NewTextExtractor(),
+		strategies: map[string]ChunkingStrategy{
+			ChunkingFixed:     chunker,
+			ChunkingRecursive: NewRecursiveChunker(chunker),
+			ChunkingSemantic:  NewSemanticChunker(chunker, embedder, chunking.SemanticMinTokens, chunking.SemanticBreakpointPercentile),
+		},
+		defaultStrategy: chunking.Strategy,
+		search:          search,
+		topK:            topK,
+		threshold:       threshold,
+	}
+}
+
+// upload document
+func (uc *DocumentUsecase) UploadDocument(
+	ctx context.Context,
+	userID string,
+	filename string,
+	fileData []byte,
+	mimeType string,
+	visibility entity.DocumentVisibility,
+	chunkingStrategy string,
+) (*entity.Document, error) {
+
+	// reject files that could never be processed
+	if !uc.supports(mimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, mimeType)
+	}
+	if chunkingStrategy != "" && !uc.SupportsChunkingStrategy(chunkingStrategy) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChunkingStrategy, chunkingStrategy)
+	}
+
+	// create document record
+	doc := &entity.Document{
+		UserID:           userID,
+		Filename:         fmt.Sprintf("%s_%d_%s", userID, time.Now().Unix(), filename),
+		OriginalName:     filename,
+		FileSize:         int64(len(fileData)),
+		MimeType:         mimeType,
+		Status:           entity.StatusProcessing,
+		Visibility:       visibility,
+		TotalChunks:      0,
+		ChunkingStrategy: chunkingStrategy,
+	}
+
+	if err := uc.docRepo.Create(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	// keep the original file, so it can be downloaded and reprocessed
+	if err := uc.blobStore.Put(ctx, blobKey(doc), fileData, mimeType); err != nil {
+		uc.docRepo.UpdateStatus(ctx, doc.ID, entity.StatusFailed)
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	// queue document for processing by the ingestion workers, which read the
+	// file back from blob storage
+	job := &entity.IngestionJob{
+		DocumentID: doc.ID,
+		MimeType:   mimeType,
+	}
+	if err := uc.jobRepo.Enqueue(ctx, job); err != nil {
+		uc.docRepo.UpdateStatus(ctx, doc.ID, entity.StatusFailed)
+		return nil, fmt.Errorf("failed to queue document for processing: %w", err)
+	}
+
+	return doc, nil
+
+}
+
+// blobKey is where the original file of a document is stored. Filenames only
+// differ by the second of the upload, so they can't be used as keys.
+func blobKey(doc *entity.Document) string {
+	return doc.ID
+}
+
+// process document, reading the original file from blob storage
+func (uc DocumentUsecase) ProcessDocument(
+	ctx context.Context,
+	documentID string,
+	mimeType string,
+) error {
+	log.Printf("Starting processing for document %s", documentID)
+
+	doc, err := uc.docRepo.FindByID(ctx, documentID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return ErrDocumentNotFound
+	}
+
+	strategy, err := uc.strategyFor(doc)
+	if err != nil {
+		return err
+	}
+
+	fileData, err := uc.loadOriginal(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	// 1 extract text
+	segments, err := uc.extractSegments(ctx, documentID, mimeType, fileData)
+	if err != nil {
+		return fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	if len(segments) == 0 {
+		return fmt.Errorf("no text extracted from document")
+	}
+	log.Printf("Extracted %d segments from document %s", len(segments), documentID)
+
+	// 2 chunk text
+	textChunks, err := strategy.Chunk(ctx, segments)
+	if err != nil {
+		return fmt.Errorf("failed to chunk text: %w", err)
+	}
+	if len(textChunks) == 0 {
+		return fmt.Errorf("no chunks generated")
+	}
+	log.Printf("Generated %d chunks from document %s", len(textChunks), documentID)
+
+	contents := make([]string, len(textChunks))
+	for i, chunk := range textChunks {
+		contents[i] = chunk.Content
+	}
+
+	// 3 generate embeddings
+	embeddings, err := uc.embedder.GenerateBatchEmbeddings(ctx, contents)
+	if err != nil {
+		return fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	log.Printf("Generated %d embeddings from document %s", len(embeddings), documentID)
+
+	// 4 create chunks with embeddings
+	var chunks []entity.DocumentChunk
+	for i, chunk := range textChunks {
+		source := "text"
+		if chunk.OCR {
+			source = "ocr"
+		}
+		metadata, _ := json.Marshal(entity.ChunkMetadata{
+			Source:         source,
+			PageNumber:     chunk.First.PageNumber,
+			StartPage:      chunk.First.PageNumber,
+			EndPage:        chunk.Last.PageNumber,
+			SlideNumber:    chunk.First.SlideNumber,
+			EndSlideNumber: chunk.Last.SlideNumber,
+			HeadingPath:    chunk.First.HeadingPath,
+			SheetName:      chunk.First.SheetName,
+			StartRow:       chunk.StartRow,
+			EndRow:         chunk.EndRow,
+			Confidence:     chunk.Confidence,
+		})
+		chunks = append(chunks, entity.DocumentChunk{
+			DocumentID: documentID,
+			ChunkIndex: i,
+			Content:    chunk.Content,
+			Embedding:  embeddings[i],
+			Metadata:   metadata,
+		})
+	}
+
+	// 5 save chunks, atomically replacing those of an earlier run
+	if err := uc.chunkRepo.ReplaceByDocumentID(ctx, documentID, chunks); err != nil {
+		return fmt.Errorf("failed to save chunks: %w", err)
+	}
+	log.Printf("Saved %d chunks to database for document %s", len(chunks), documentID)
+
+	// 6 update document status
+	if err := uc.docRepo.UpdateTotalChunks(ctx, documentID, len(chunks)); err != nil {
+		return err
+	}
+
+	if err := uc.docRepo.UpdateStatus(ctx, documentID, entity.StatusCompleted); err != nil {
+		return err
+	}
+
+	log.Printf("Document %s processed successfully with %d chunks", documentID, len(chunks))
+	return nil
+
+}
+
+// list document
+func (uc *DocumentUsecase) ListDocuments(
+	ctx context.Context,
+	userID string,
+	page, limit int,
+) ([]entity.Document, int, error) {
+	return uc.docRepo.List(ctx, userID, page, limit)
+}
+
+// get document by id
+func (uc *DocumentUsecase) GetDocumentByID(
+	ctx context.Context,
+	documentID string,
+	userID string,
+) (*entity.Document, error) {
+	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, nil
+	}
+
+	return doc, nil
+
+}
+
+// delete
+func (uc *DocumentUsecase) DeleteDocument(
+	ctx context.Context,
+	documentID string,
+	userID string,
+) error {
+	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return fmt.Errorf("document not found")
+	}
+
+	// Delete chunks first
+	if err := uc.chunkRepo.DeleteByDocumentID(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := uc.docRepo.Delete(ctx, documentID); err != nil {
+		return err
+	}
+
+	// the document is gone already, an orphaned file only wastes space
+	if err := uc.blobStore.Delete(ctx, blobKey(doc)); err != nil {
+		log.Printf("Failed to delete file of document %s: %v", documentID, err)
+	}
+
+	return nil
+
+}
+
+// download the original file of a document, nil when it was uploaded before
+// files were kept
+func (uc *DocumentUsecase) DownloadDocument(
+	ctx context.Context,
+	documentID string,
+	userID string,
+) (*entity.Document, []byte, error) {
+	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		return nil, nil, nil
+	}
+
+	data, err := uc.blobStore.Get(ctx, blobKey(doc))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return doc, data, nil
+
+}
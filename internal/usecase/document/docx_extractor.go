@@ -0,0 +1,261 @@
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// wordprocessingML namespaces, transitional and strict
+var wordNamespaces = map[string]bool{
+	"http://schemas.openxmlformats.org/wordprocessingml/2006/main": true,
+	"http://purl.oclc.org/ooxml/wordprocessingml/main":             true,
+}
+
+// DOCXExtractor reads the body of a Word document. Headings are written as
+// markdown headings, paragraphs are separated by blank lines and table rows
+// become "cell | cell" lines, so the structure survives as plain text.
+type DOCXExtractor struct{}
+
+// Extract returns one segment per heading section, docx has no fixed pages
+func (e *DOCXExtractor) Extract(data []byte) ([]Segment, error) {
+	zr, err := openOOXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readOOXMLPart(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("not a Word document: word/document.xml is missing")
+	}
+
+	styles, err := readOOXMLPart(zr, "word/styles.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := parseDOCXBody(body, parseHeadingStyles(styles))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Word document: %w", err)
+	}
+
+	return segments, nil
+}
+
+// parseHeadingStyles maps paragraph style IDs to their heading level. Style
+// IDs are localized, so the level comes from the outline level or the
+// built-in style name instead of the ID.
+func parseHeadingStyles(data []byte) map[string]int {
+	levels := make(map[string]int)
+	if data == nil {
+		return levels
+	}
+
+	var doc struct {
+		Styles []struct {
+			Type    string `xml:"type,attr"`
+			StyleID string `xml:"styleId,attr"`
+			Name    xmlVal `xml:"name"`
+			PPr     struct {
+				OutlineLvl *xmlVal `xml:"outlineLvl"`
+			} `xml:"pPr"`
+		} `xml:"style"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return levels
+	}
+
+	for _, style := range doc.Styles {
+		if style.Type != "paragraph" {
+			continue
+		}
+		if level := headingLevel(style.Name.Val, style.PPr.OutlineLvl); level > 0 {
+			levels[style.StyleID] = level
+		}
+	}
+	return levels
+}
+
+// xmlVal is an element whose value is in its w:val attribute
+type xmlVal struct {
+	Val string `xml:"val,attr"`
+}
+
+func headingLevel(name string, outlineLvl *xmlVal) int {
+	if outlineLvl != nil {
+		// outline level 9 means body text
+		if lvl, err := strconv.Atoi(outlineLvl.Val); err == nil && lvl >= 0 && lvl < 9 {
+			return lvl + 1
+		}
+	}
+
+	name = strings.ToLower(name)
+	if name == "title" {
+		return 1
+	}
+	if rest, ok := strings.CutPrefix(name, "heading "); ok {
+		if lvl, err := strconv.Atoi(rest); err == nil && lvl > 0 && lvl <= 9 {
+			return lvl
+		}
+	}
+	return 0
+}
+
+type docxParagraph struct {
+	text  strings.Builder
+	level int
+}
+
+type docxTable struct {
+	rows [][]string
+	row  []string
+	cell []string
+}
+
+// parseDOCXBody walks document.xml, adding one block per paragraph or table
+func parseDOCXBody(data []byte, headingStyles map[string]int) ([]Segment, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var sections sectionBuilder
+	var paragraphs []*docxParagraph
+	var tables []*docxTable
+	inText := false
+
+	writeBlock := func(block string) {
+		if len(tables) > 0 {
+			// text inside a table belongs to the current cell
+			table := tables[len(tables)-1]
+			table.cell = append(table.cell, strings.Join(strings.Fields(block), " "))
+			return
+		}
+		sections.block(block)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			// alternate content repeats the same text for older readers
+			if el.Name.Local == "Fallback" {
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if !wordNamespaces[el.Name.Space] {
+				continue
+			}
+
+			switch el.Name.Local {
+			case "p":
+				paragraphs = append(paragraphs, &docxParagraph{})
+			case "pStyle":
+				if len(paragraphs) > 0 {
+					paragraph := paragraphs[len(paragraphs)-1]
+					if level := headingStyles[attr(el, "val")]; level > 0 {
+						paragraph.level = level
+					}
+				}
+			case "outlineLvl":
+				if len(paragraphs) > 0 {
+					if lvl, err := strconv.Atoi(attr(el, "val")); err == nil && lvl >= 0 && lvl < 9 {
+						paragraphs[len(paragraphs)-1].level = lvl + 1
+					}
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if len(paragraphs) > 0 {
+					paragraphs[len(paragraphs)-1].text.WriteByte('\t')
+				}
+			case "br", "cr":
+				if len(paragraphs) > 0 {
+					paragraphs[len(paragraphs)-1].text.WriteByte('\n')
+				}
+			case "tbl":
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].row = nil
+				}
+			case "tc":
+				if len(tables) > 0 {
+					tables[len(tables)-1].cell = nil
+				}
+			}
+
+		case xml.CharData:
+			if inText && len(paragraphs) > 0 {
+				paragraphs[len(paragraphs)-1].text.Write(el)
+			}
+
+		case xml.EndElement:
+			if !wordNamespaces[el.Name.Space] {
+				continue
+			}
+
+			switch el.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if len(paragraphs) == 0 {
+					continue
+				}
+				paragraph := paragraphs[len(paragraphs)-1]
+				paragraphs = paragraphs[:len(paragraphs)-1]
+
+				text := strings.TrimSpace(paragraph.text.String())
+				if text == "" {
+					continue
+				}
+				if paragraph.level > 0 && len(tables) == 0 {
+					sections.heading(paragraph.level, text)
+					continue
+				}
+				writeBlock(text)
+			case "tc":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.row = append(table.row, strings.Join(table.cell, " "))
+				}
+			case "tr":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.rows = append(table.rows, table.row)
+				}
+			case "tbl":
+				if len(tables) == 0 {
+					continue
+				}
+				table := tables[len(tables)-1]
+				tables = tables[:len(tables)-1]
+
+				var lines []string
+				for _, row := range table.rows {
+					if strings.TrimSpace(strings.Join(row, "")) == "" {
+						continue
+					}
+					lines = append(lines, strings.Join(row, " | "))
+				}
+				if len(lines) > 0 {
+					writeBlock(strings.Join(lines, "\n"))
+				}
+			}
+		}
+	}
+
+	return sections.result(), nil
+}
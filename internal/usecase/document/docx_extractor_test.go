@@ -0,0 +1,94 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+
+// wordParagraph writes a paragraph, with a paragraph style when style isn't empty
+func wordParagraph(style, text string) string {
+	pPr := ""
+	if style != "" {
+		pPr = `<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`
+	}
+	return `<w:p>` + pPr + `<w:r><w:t>` + text + `</w:t></w:r></w:p>`
+}
+
+func buildDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+	return buildZip(t, map[string]string{
+		"word/document.xml": `<w:document ` + wordNS + `><w:body>` + body + `</w:body></w:document>`,
+		// localized style IDs, the levels come from the names and outline levels
+		"word/styles.xml": `<w:styles ` + wordNS + `>` +
+			`<w:style w:type="paragraph" w:styleId="Judul1"><w:name w:val="heading 1"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Judul2"><w:name w:val="heading 2"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Subbab"><w:name w:val="Subbab"/><w:pPr><w:outlineLvl w:val="2"/></w:pPr></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Kutipan"><w:name w:val="Quote"/></w:style>` +
+			`</w:styles>`,
+	})
+}
+
+func TestDOCXExtractorSplitsSectionsByHeading(t *testing.T) {
+	data := buildDOCX(t, wordParagraph("", "Pendahuluan singkat.")+
+		wordParagraph("Judul1", "Bab 1")+
+		wordParagraph("Kutipan", "Isi bab satu.")+
+		wordParagraph("Judul2", "Graf")+
+		wordParagraph("Subbab", "Graf berarah")+
+		`<w:p><w:r><w:t>Graf</w:t><w:tab/><w:t>berarah.</w:t><w:br/><w:t>Baris baru.</w:t></w:r></w:p>`+
+		`<w:tbl><w:tr><w:tc>`+wordParagraph("", "Simpul")+`</w:tc><w:tc>`+wordParagraph("", "Sisi")+`</w:tc></w:tr>`+
+		`<w:tr><w:tc>`+wordParagraph("Judul1", "3")+`</w:tc><w:tc>`+wordParagraph("", "2")+`</w:tc></w:tr></w:tbl>`+
+		// a heading set on the paragraph itself
+		`<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>Bab 2</w:t></w:r></w:p>`+
+		// alternate content holds the same text twice
+		`<w:p><w:r><mc:AlternateContent xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006">`+
+		`<mc:Choice Requires="wps"><w:t>Kotak teks.</w:t></mc:Choice><mc:Fallback><w:t>Kotak teks.</w:t></mc:Fallback>`+
+		`</mc:AlternateContent></w:r></w:p>`)
+
+	segments, err := (&DOCXExtractor{}).Extract(data)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	want := []Segment{
+		{Text: "Pendahuluan singkat."},
+		{Text: "# Bab 1\n\nIsi bab satu.", HeadingPath: []string{"Bab 1"}},
+		{
+			// a heading directly followed by a subheading shares its section
+			Text:        "## Graf\n\n### Graf berarah\n\nGraf\tberarah.\nBaris baru.\n\nSimpul | Sisi\n3 | 2",
+			HeadingPath: []string{"Bab 1", "Graf", "Graf berarah"},
+		},
+		{Text: "# Bab 2\n\nKotak teks.", HeadingPath: []string{"Bab 2"}},
+	}
+	if !reflect.DeepEqual(segments, want) {
+		t.Errorf("got segments\n%#v\nwant\n%#v", segments, want)
+	}
+}
+
+func TestDOCXExtractorRejectsInvalidPackages(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not a zip", []byte("bukan dokumen"), "failed to open document package"},
+		{"no document part", buildZip(t, map[string]string{"word/styles.xml": "<w:styles/>"}), "word/document.xml is missing"},
+		{
+			// a small zip that inflates beyond the part size limit
+			name: "zip bomb",
+			data: buildZip(t, map[string]string{
+				"word/document.xml": strings.Repeat(" ", maxOOXMLPartSize+1),
+			}),
+			want: "word/document.xml is too large",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := (&DOCXExtractor{}).Extract(test.data)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+// names of the query expansion strategies
+const (
+	ExpansionNone = "none"
+	// ExpansionMultiQuery also searches paraphrases of the query
+	ExpansionMultiQuery = "multi-query"
+	// ExpansionHyDE also searches a hypothetical answer, which is worded like
+	// the documents rather than like a question
+	ExpansionHyDE = "hyde"
+)
+
+var ErrUnknownExpansion = errors.New("unknown query expansion")
+
+// QueryExpander writes other texts to search for a query with
+type QueryExpander interface {
+	// Paraphrase returns up to n different wordings of the query
+	Paraphrase(ctx context.Context, query string, n int) ([]string, error)
+	// HypotheticalAnswer writes a plausible answer to the query, whether or
+	// not it is correct
+	HypotheticalAnswer(ctx context.Context, query string) (string, error)
+}
+
+// SupportsExpansion reports whether name is a known query expansion strategy
+func (uc *DocumentUsecase) SupportsExpansion(name string) bool {
+	return name == ExpansionNone || name == ExpansionMultiQuery || name == ExpansionHyDE
+}
+
+// expandQuery returns the texts written by the expansion strategy, none when
+// it is off or fails, so retrieval never breaks because of it
+func (uc *DocumentUsecase) expandQuery(ctx context.Context, query, expansion string) []string {
+	if uc.expander == nil {
+		return nil
+	}
+
+	var expansions []string
+	var err error
+	switch expansion {
+	case ExpansionMultiQuery:
+		expansions, err = uc.expander.Paraphrase(ctx, query, uc.search.MultiQueryCount)
+	case ExpansionHyDE:
+		var answer string
+		answer, err = uc.expander.HypotheticalAnswer(ctx, query)
+		expansions = []string{answer}
+	default:
+		return nil
+	}
+	if err != nil {
+		log.Printf("Failed to expand query with %s, using original: %v", expansion, err)
+		return nil
+	}
+
+	// drop empty texts and repeats of the query
+	seen := map[string]bool{strings.ToLower(query): true}
+	var texts []string
+	for _, text := range expansions {
+		text = strings.TrimSpace(text)
+		if text == "" || seen[strings.ToLower(text)] {
+			continue
+		}
+		seen[strings.ToLower(text)] = true
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// searchExpanded searches for the query and every expansion, then fuses the
+// rankings with reciprocal rank fusion. A chunk keeps its highest similarity
+// to any of the texts.
+func (uc *DocumentUsecase) searchExpanded(
+	ctx context.Context,
+	queries []string,
+	filter repository.ChunkSearchFilter,
+	opts QueryOptions,
+) ([]entity.SimilarChunk, error) {
+	embeddings, err := uc.embedder.GenerateBatchEmbeddings(ctx, queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to  generate query embedding: %w", err)
+	}
+	if len(embeddings) != len(queries) {
+		return nil, fmt.Errorf("no embedding generated for query")
+	}
+
+	var rankings [][]entity.SimilarChunk
+	for i, query := range queries {
+		chunks, err := uc.searchChunks(ctx, embeddings[i], query, filter, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search similar chunks: %w", err)
+		}
+		rankings = append(rankings, chunks)
+	}
+	if len(rankings) == 1 {
+		return rankings[0], nil
+	}
+
+	fused := make(map[string]*entity.SimilarChunk)
+	var order []string
+	for _, ranking := range rankings {
+		for rank, chunk := range ranking {
+			score := 1 / float64(uc.search.RRFK+rank+1)
+			if existing, ok := fused[chunk.ID]; ok {
+				existing.Score += score
+				existing.Similarity = max(existing.Similarity, chunk.Similarity)
+				continue
+			}
+			chunk.Score = score
+			fused[chunk.ID] = &chunk
+			order = append(order, chunk.ID)
+		}
+	}
+
+	chunks := make([]entity.SimilarChunk, len(order))
+	for i, id := range order {
+		chunks[i] = *fused[id]
+	}
+	// ties keep the order of the original query's ranking
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Score > chunks[j].Score
+	})
+
+	return chunks[:min(filter.TopK, len(chunks))], nil
+}
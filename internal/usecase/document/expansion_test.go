@@ -0,0 +1,103 @@
+package document_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/domain/repository"
+	"rag-api/internal/usecase/document"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// recordingEmbedder remembers the texts of every batch it embeds
+type recordingEmbedder struct {
+	embedder *fake.EmbeddingClient
+	batches  [][]string
+}
+
+func newRecordingEmbedder() *recordingEmbedder {
+	return &recordingEmbedder{embedder: fake.NewEmbeddingClient(1536)}
+}
+
+func (e *recordingEmbedder) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	e.batches = append(e.batches, append([]string(nil), texts...))
+	return e.embedder.GenerateBatchEmbeddings(ctx, texts)
+}
+
+func TestQueryExpansionSearchesAndFusesExpandedQueries(t *testing.T) {
+	const query = "Bagaimana algoritma Dijkstra mencari lintasan terpendek?"
+	chat := fake.NewChatClient()
+	paraphrases, _ := chat.Paraphrase(context.Background(), query, 2)
+	answer, _ := chat.HypotheticalAnswer(context.Background(), query)
+
+	tests := []struct {
+		expansion  string
+		expansions []string
+	}{
+		{document.ExpansionMultiQuery, paraphrases},
+		{document.ExpansionHyDE, []string{answer}},
+	}
+
+	for _, test := range tests {
+		embedder := newRecordingEmbedder()
+		p := newPipeline(t, pipelineOptions{
+			embedder: embedder,
+			search: document.SearchConfig{
+				Mode:            document.SearchModeVector,
+				RRFK:            60,
+				MMRLambda:       1,
+				Expansion:       test.expansion,
+				MultiQueryCount: 2,
+			},
+		})
+		doc := p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+		result, err := p.docs.RetrieveChunks(context.Background(), p.user.ID, query, document.QueryOptions{})
+		if err != nil {
+			t.Fatalf("%s: RetrieveChunks: %v", test.expansion, err)
+		}
+		if !reflect.DeepEqual(result.Expansions, test.expansions) {
+			t.Errorf("%s: got expansions %q, want %q", test.expansion, result.Expansions, test.expansions)
+		}
+
+		// the query and its expansions are embedded together and searched
+		queries := append([]string{query}, test.expansions...)
+		if got := embedder.batches[len(embedder.batches)-1]; !reflect.DeepEqual(got, queries) {
+			t.Fatalf("%s: got embedded queries %q, want %q", test.expansion, got, queries)
+		}
+
+		// every chunk scores the reciprocal ranks it has in the searches
+		embeddings, _ := embedder.embedder.GenerateBatchEmbeddings(context.Background(), queries)
+		want := make(map[string]float64)
+		for _, embedding := range embeddings {
+			ranking, err := p.chunkRepo.SearchSimilar(context.Background(), embedding, repository.ChunkSearchFilter{
+				UserID:    p.user.ID,
+				TopK:      3,
+				Threshold: 0.1,
+			})
+			if err != nil {
+				t.Fatalf("%s: SearchSimilar: %v", test.expansion, err)
+			}
+			for rank, chunk := range ranking {
+				want[chunk.ID] += 1 / float64(60+rank+1)
+			}
+		}
+
+		if len(result.Chunks) != min(3, len(want), doc.TotalChunks) {
+			t.Fatalf("%s: got %d chunks, want up to 3 of the %d found", test.expansion, len(result.Chunks), len(want))
+		}
+		if !sort.SliceIsSorted(result.Chunks, func(i, j int) bool { return result.Chunks[i].Score > result.Chunks[j].Score }) {
+			t.Errorf("%s: got chunks out of score order", test.expansion)
+		}
+		for _, chunk := range result.Chunks {
+			if math.Abs(chunk.Score-want[chunk.ID]) > 1e-12 {
+				t.Errorf("%s: chunk %q has score %v, want %v", test.expansion, chunk.Content[:20], chunk.Score, want[chunk.ID])
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// elements that hold no readable content, or only site chrome
+var skippedHTMLElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Canvas:   true,
+}
+
+var skippedHTMLRoles = map[string]bool{
+	"navigation":  true,
+	"banner":      true,
+	"contentinfo": true,
+	"search":      true,
+	"menu":        true,
+}
+
+var blockHTMLElements = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Div:        true,
+	atom.Section:    true,
+	atom.Article:    true,
+	atom.Main:       true,
+	atom.Header:     true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Dd:         true,
+	atom.Figure:     true,
+	atom.Figcaption: true,
+	atom.Details:    true,
+	atom.Summary:    true,
+	atom.Address:    true,
+	atom.Hr:         true,
+}
+
+var htmlHeadingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+	atom.H4: 4,
+	atom.H5: 5,
+	atom.H6: 6,
+}
+
+// HTMLExtractor reads the main content of a web page, dropping scripts,
+// styles and navigation. It returns one segment per heading section, with
+// the heading hierarchy as its HeadingPath.
+type HTMLExtractor struct{}
+
+func (e *HTMLExtractor) Extract(data []byte) ([]Segment, error) {
+	// the encoding comes from a BOM or <meta charset>, UTF-8 by default
+	reader, err := charset.NewReader(bytes.NewReader(data), "text/html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect HTML encoding: %w", err)
+	}
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	// pages that mark their main content don't need the rest
+	root := findHTMLElement(doc, atom.Main)
+	if root == nil {
+		root = doc
+	}
+
+	w := &htmlWalker{}
+	w.walk(root)
+	w.endBlock()
+
+	return w.sections.result(), nil
+}
+
+type htmlWalker struct {
+	sections sectionBuilder
+	inline   strings.Builder
+}
+
+func (w *htmlWalker) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.inline.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.walkChildren(n)
+		return
+	}
+
+	if skipHTMLNode(n) {
+		return
+	}
+
+	if level, ok := htmlHeadingLevels[n.DataAtom]; ok {
+		w.endBlock()
+		w.sections.heading(level, htmlText(n))
+		return
+	}
+
+	switch {
+	case n.DataAtom == atom.Br:
+		w.inline.WriteByte('\n')
+	case n.DataAtom == atom.Table:
+		w.endBlock()
+		w.sections.block(htmlTable(n))
+	case n.DataAtom == atom.Li:
+		w.endBlock()
+		w.inline.WriteString("- ")
+		w.walkChildren(n)
+		w.endBlock()
+	case blockHTMLElements[n.DataAtom]:
+		w.endBlock()
+		w.walkChildren(n)
+		w.endBlock()
+	default:
+		w.walkChildren(n)
+	}
+}
+
+func (w *htmlWalker) walkChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.walk(child)
+	}
+}
+
+func (w *htmlWalker) endBlock() {
+	text := strings.TrimSpace(w.inline.String())
+	if text != "" && text != "-" {
+		w.sections.block(text)
+	}
+	w.inline.Reset()
+}
+
+func skipHTMLNode(n *html.Node) bool {
+	if skippedHTMLElements[n.DataAtom] {
+		return true
+	}
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "hidden":
+			return true
+		case a.Key == "aria-hidden" && a.Val == "true":
+			return true
+		case a.Key == "role" && skippedHTMLRoles[a.Val]:
+			return true
+		}
+	}
+	return false
+}
+
+// htmlText returns the visible text of a node on one line
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+			return
+		}
+		if n.Type == html.ElementNode && skipHTMLNode(n) {
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// htmlTable writes every row of a table as a "cell | cell" line
+func htmlTable(table *html.Node) string {
+	var lines []string
+	var rows func(n *html.Node)
+	rows = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+						cells = append(cells, htmlText(cell))
+					}
+				}
+				if strings.TrimSpace(strings.Join(cells, "")) != "" {
+					lines = append(lines, strings.Join(cells, " | "))
+				}
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				rows(child)
+			}
+		}
+	}
+	rows(table)
+	return strings.Join(lines, "\n")
+}
+
+// findHTMLElement returns the first element of the kind, depth first
+func findHTMLElement(n *html.Node, kind atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == kind {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findHTMLElement(child, kind); found != nil {
+			return found
+		}
+	}
+	return nil
+}
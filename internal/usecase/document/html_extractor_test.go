@@ -0,0 +1,64 @@
+package document_test
+
+import (
+	"reflect"
+	"testing"
+
+	"rag-api/internal/usecase/document"
+)
+
+func TestHTMLExtractor(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []document.Segment
+	}{
+		{
+			name: "heading sections of the main content",
+			html: `<html><head><title>Judul</title><style>body { color: red }</style><script>var x = 1;</script></head>
+<body><nav>Beranda | Profil</nav>
+<main>
+<h1>Teori Graf</h1>
+<p>Graf terdiri dari <b>simpul</b> dan sisi.<script>track("graf")</script></p>
+<noscript>Aktifkan JavaScript</noscript>
+<h2>Derajat <span aria-hidden="true">¶</span></h2>
+<ul><li>Derajat masuk</li><li>Derajat keluar</li></ul>
+<style>.tabel { border: 0 }</style>
+<table><tr><th>Simpul</th><th>Derajat</th></tr><tr><td>A</td><td>2</td></tr></table>
+<h1>Basis Data</h1>
+<div hidden>rahasia</div>
+<p>Tabel dan baris.</p>
+</main>
+<footer>Hak cipta</footer></body></html>`,
+			want: []document.Segment{
+				{Text: "# Teori Graf\n\nGraf terdiri dari simpul dan sisi.", HeadingPath: []string{"Teori Graf"}},
+				{
+					Text:        "## Derajat\n\n- Derajat masuk\n\n- Derajat keluar\n\nSimpul | Derajat\nA | 2",
+					HeadingPath: []string{"Teori Graf", "Derajat"},
+				},
+				{Text: "# Basis Data\n\nTabel dan baris.", HeadingPath: []string{"Basis Data"}},
+			},
+		},
+		{
+			name: "page without main drops scripts, styles and chrome",
+			html: `<!DOCTYPE html><html><head><script src="app.js"></script><style>p { margin: 0 }</style></head>
+<body><div role="navigation">Menu</div><header><h1>Catatan</h1></header>
+<script>document.write("iklan")</script>
+<p>Isi <style>b { }</style>catatan.<br>Baris kedua.</p>
+<aside>Tautan terkait</aside><form><button>Kirim</button></form></body></html>`,
+			want: []document.Segment{
+				{Text: "# Catatan\n\nIsi catatan.\nBaris kedua.", HeadingPath: []string{"Catatan"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		segments, err := (&document.HTMLExtractor{}).Extract([]byte(test.html))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(segments, test.want) {
+			t.Errorf("%s: got segments\n%#v\nwant\n%#v", test.name, segments, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextHeading1 = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextHeading2 = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	codeFence      = regexp.MustCompile("^ {0,3}(```|~~~)")
+)
+
+// PlainTextExtractor returns a text file as it is
+type PlainTextExtractor struct{}
+
+func (e *PlainTextExtractor) Extract(data []byte) ([]Segment, error) {
+	text := decodeText(data)
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	return []Segment{{Text: text}}, nil
+}
+
+// MarkdownExtractor returns one segment per heading section, with the heading
+// hierarchy as its HeadingPath
+type MarkdownExtractor struct{}
+
+func (e *MarkdownExtractor) Extract(data []byte) ([]Segment, error) {
+	lines := strings.Split(decodeText(data), "\n")
+	lines = skipFrontMatter(lines)
+
+	var sections sectionBuilder
+	var paragraph []string
+	fence := ""
+
+	endParagraph := func() {
+		sections.block(strings.Join(paragraph, "\n"))
+		paragraph = nil
+	}
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+
+		// code blocks are kept whole, a "#" inside is not a heading
+		if fence != "" {
+			paragraph = append(paragraph, line)
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+				endParagraph()
+			}
+			continue
+		}
+		if m := codeFence.FindStringSubmatch(line); m != nil {
+			endParagraph()
+			fence = m[1]
+			paragraph = append(paragraph, line)
+			continue
+		}
+
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			endParagraph()
+			sections.heading(len(m[1]), m[2])
+			continue
+		}
+
+		// setext headings underline the paragraph before them
+		if len(paragraph) > 0 && setextHeading1.MatchString(line) {
+			text := strings.Join(paragraph, " ")
+			paragraph = nil
+			sections.heading(1, text)
+			continue
+		}
+		if len(paragraph) > 0 && setextHeading2.MatchString(line) {
+			text := strings.Join(paragraph, " ")
+			paragraph = nil
+			sections.heading(2, text)
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			endParagraph()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	endParagraph()
+
+	return sections.result(), nil
+}
+
+// skipFrontMatter drops a leading YAML front matter block of exported notes
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+// decodeText drops a UTF-8 byte order mark and replaces invalid bytes
+func decodeText(data []byte) string {
+	text := strings.TrimPrefix(string(data), "\uFEFF")
+	return strings.ToValidUTF8(text, "\uFFFD")
+}
@@ -0,0 +1,48 @@
+package document_test
+
+import (
+	"reflect"
+	"testing"
+
+	"rag-api/internal/usecase/document"
+)
+
+func TestMarkdownExtractorSplitsSectionsByHeading(t *testing.T) {
+	markdown := "---\ntitle: Catatan\n---\n" +
+		"Pengantar.\n\n" +
+		"Teori Graf\n==========\n\n" +
+		"Graf terdiri dari simpul.\n\n" +
+		"## Derajat ##\n\n" +
+		"Derajat simpul.\n" +
+		"```python\n# bukan heading\nprint(1)\n```\n\n" +
+		"Lintasan\n--------\n" +
+		"Isi lintasan.\r\n\r\n" +
+		"# Basis Data\n\n" +
+		"### Normalisasi\n" +
+		"Mengurangi redundansi.\n"
+
+	segments, err := (&document.MarkdownExtractor{}).Extract([]byte(markdown))
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	want := []document.Segment{
+		{Text: "Pengantar."},
+		{Text: "# Teori Graf\n\nGraf terdiri dari simpul.", HeadingPath: []string{"Teori Graf"}},
+		{
+			// the "#" inside the code block is not a heading
+			Text:        "## Derajat\n\nDerajat simpul.\n\n```python\n# bukan heading\nprint(1)\n```",
+			HeadingPath: []string{"Teori Graf", "Derajat"},
+		},
+		{Text: "## Lintasan\n\nIsi lintasan.", HeadingPath: []string{"Teori Graf", "Lintasan"}},
+		{
+			// a level can be skipped, and a heading directly followed by a
+			// subheading shares its section
+			Text:        "# Basis Data\n\n### Normalisasi\n\nMengurangi redundansi.",
+			HeadingPath: []string{"Basis Data", "Normalisasi"},
+		},
+	}
+	if !reflect.DeepEqual(segments, want) {
+		t.Errorf("got segments\n%#v\nwant\n%#v", segments, want)
+	}
+}
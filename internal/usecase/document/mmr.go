@@ -0,0 +1,71 @@
+package document
+
+import (
+	"math"
+
+	"rag-api/internal/domain/entity"
+)
+
+// selectMMR picks topK of the candidates with maximal marginal relevance:
+// each pick maximizes lambda*relevance - (1-lambda)*similarity to the chunks
+// already picked. Lambda 1 keeps the candidates' order; lower values skip
+// near duplicates, like overlapping neighbours, in favour of chunks adding
+// new information. Candidates must be ordered by relevance.
+func selectMMR(candidates []entity.SimilarChunk, topK int, lambda float64) []entity.SimilarChunk {
+	if lambda >= 1 || len(candidates) <= topK {
+		return candidates[:min(topK, len(candidates))]
+	}
+
+	selected := make([]entity.SimilarChunk, 0, topK)
+	remaining := append([]entity.SimilarChunk(nil), candidates...)
+	relevance := relevances(remaining)
+	// highest similarity of every remaining candidate to the selected chunks
+	redundancy := make([]float64, len(remaining))
+
+	for len(selected) < topK && len(remaining) > 0 {
+		best, bestScore := 0, math.Inf(-1)
+		for i := range remaining {
+			score := lambda*relevance[i] - (1-lambda)*redundancy[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		picked := remaining[best]
+		selected = append(selected, picked)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		relevance = append(relevance[:best], relevance[best+1:]...)
+		redundancy = append(redundancy[:best], redundancy[best+1:]...)
+
+		for i, candidate := range remaining {
+			similarity := cosineSimilarity(picked.Embedding.Slice(), candidate.Embedding.Slice())
+			redundancy[i] = max(redundancy[i], similarity)
+		}
+	}
+
+	return selected
+}
+
+// relevances are the reranker scores when the chunks were reranked, else the
+// fused scores of a hybrid or expanded search, else the similarity to the
+// query. Fused scores only compare ranks, so they are scaled for the best
+// chunk to score 1, like the similarity of an exact match.
+func relevances(chunks []entity.SimilarChunk) []float64 {
+	maxScore := 0.0
+	for _, chunk := range chunks {
+		maxScore = max(maxScore, chunk.Score)
+	}
+
+	values := make([]float64, len(chunks))
+	for i, chunk := range chunks {
+		switch {
+		case chunk.RerankScore != nil:
+			values[i] = *chunk.RerankScore
+		case maxScore > 0:
+			values[i] = chunk.Score / maxScore
+		default:
+			values[i] = chunk.Similarity
+		}
+	}
+	return values
+}
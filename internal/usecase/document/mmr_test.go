@@ -0,0 +1,69 @@
+package document
+
+import (
+	"testing"
+
+	"rag-api/internal/domain/entity"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+func candidate(id string, similarity, score float64, embedding ...float32) entity.SimilarChunk {
+	return entity.SimilarChunk{
+		DocumentChunk: entity.DocumentChunk{ID: id, Embedding: pgvector.NewVector(embedding)},
+		Similarity:    similarity,
+		Score:         score,
+	}
+}
+
+func ids(chunks []entity.SimilarChunk) []string {
+	result := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		result[i] = chunk.ID
+	}
+	return result
+}
+
+func TestSelectMMRSkipsNearDuplicates(t *testing.T) {
+	candidates := []entity.SimilarChunk{
+		candidate("a", 0.9, 0, 1, 0),
+		candidate("a-copy", 0.89, 0, 1, 0.01),
+		candidate("b", 0.7, 0, 0, 1),
+	}
+
+	if got := ids(selectMMR(candidates, 2, 1)); got[0] != "a" || got[1] != "a-copy" {
+		t.Errorf("got %v with lambda 1, want the candidates' order", got)
+	}
+	if got := ids(selectMMR(candidates, 2, 0.5)); got[0] != "a" || got[1] != "b" {
+		t.Errorf("got %v with lambda 0.5, want the near duplicate skipped", got)
+	}
+}
+
+func TestSelectMMRUsesFusedScores(t *testing.T) {
+	// fused ranking: "keyword" matched both rankings although it is less
+	// similar to the query, the embeddings are unrelated
+	candidates := []entity.SimilarChunk{
+		candidate("keyword", 0.2, 2.0/61, 1, 0, 0),
+		candidate("vector", 0.9, 1.0/61, 0, 1, 0),
+		candidate("other", 0.8, 1.0/62, 0, 0, 1),
+	}
+
+	got := ids(selectMMR(candidates, 2, 0.7))
+	if got[0] != "keyword" || got[1] != "vector" {
+		t.Errorf("got %v, want the fused order kept", got)
+	}
+}
+
+func TestSelectMMRPrefersRerankScores(t *testing.T) {
+	low, high := 0.1, 0.9
+	candidates := []entity.SimilarChunk{
+		candidate("a", 0.9, 2.0/61, 1, 0),
+		candidate("b", 0.2, 1.0/61, 0, 1),
+	}
+	candidates[0].RerankScore = &low
+	candidates[1].RerankScore = &high
+
+	if got := ids(selectMMR(candidates, 1, 0.7)); got[0] != "b" {
+		t.Errorf("got %v, want the chunk with the highest rerank score", got)
+	}
+}
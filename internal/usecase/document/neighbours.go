@@ -0,0 +1,141 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+// MaxNeighbourChunks limits how far around a hit the context is expanded
+const MaxNeighbourChunks = 5
+
+// minMergeOverlap is the shortest text shared by two neighbouring chunks
+// that is taken for their overlap rather than a coincidence
+const minMergeOverlap = 16
+
+// neighbourWindow is a run of consecutive chunks of one document around one
+// or more hits
+type neighbourWindow struct {
+	documentID string
+	from, to   int
+	// best is the position of the most relevant hit within the window
+	best int
+}
+
+// expandNeighbours replaces every hit with the window of chunkIndex ± n of
+// its document. Overlapping and adjacent windows are merged into one chunk,
+// so fewer chunks than hits can be returned. A merged chunk keeps the ID,
+// index and scores of its most relevant hit, in the order of the hits. Only
+// chunks of documents the user may search are added.
+func (uc *DocumentUsecase) expandNeighbours(ctx context.Context, userID string, hits []entity.SimilarChunk, n int) ([]entity.SimilarChunk, error) {
+	if n <= 0 || len(hits) == 0 {
+		return hits, nil
+	}
+
+	// 1. windows per document, merged where they touch
+	byDocument := make(map[string][]neighbourWindow)
+	for i, hit := range hits {
+		byDocument[hit.DocumentID] = append(byDocument[hit.DocumentID], neighbourWindow{
+			documentID: hit.DocumentID,
+			from:       max(hit.ChunkIndex-n, 0),
+			to:         hit.ChunkIndex + n,
+			best:       i,
+		})
+	}
+
+	var windows []neighbourWindow
+	for _, documentWindows := range byDocument {
+		sort.Slice(documentWindows, func(i, j int) bool {
+			return documentWindows[i].from < documentWindows[j].from
+		})
+		merged := []neighbourWindow{documentWindows[0]}
+		for _, window := range documentWindows[1:] {
+			last := &merged[len(merged)-1]
+			if window.from > last.to+1 {
+				merged = append(merged, window)
+				continue
+			}
+			last.to = max(last.to, window.to)
+			last.best = min(last.best, window.best)
+		}
+		windows = append(windows, merged...)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].best < windows[j].best
+	})
+
+	// 2. fetch the chunks of all windows at once
+	ranges := make([]repository.ChunkRange, len(windows))
+	for i, window := range windows {
+		ranges[i] = repository.ChunkRange{DocumentID: window.documentID, From: window.from, To: window.to}
+	}
+	neighbours, err := uc.chunkRepo.ListByRanges(ctx, userID, ranges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch neighbouring chunks: %w", err)
+	}
+
+	// 3. join every window into one chunk
+	expanded := make([]entity.SimilarChunk, 0, len(windows))
+	for _, window := range windows {
+		var chunks []entity.DocumentChunk
+		for _, neighbour := range neighbours {
+			if neighbour.DocumentID == window.documentID && neighbour.ChunkIndex >= window.from && neighbour.ChunkIndex <= window.to {
+				chunks = append(chunks, neighbour)
+			}
+		}
+
+		chunk := hits[window.best]
+		// the document was reindexed since the search, keep the hit as it was
+		if len(chunks) == 0 {
+			expanded = append(expanded, chunk)
+			continue
+		}
+
+		chunk.Content = chunks[0].Content
+		for _, next := range chunks[1:] {
+			chunk.Content = joinOverlapping(chunk.Content, next.Content)
+		}
+		chunk.Metadata = spanMetadata(chunks[0].Metadata, chunks[len(chunks)-1].Metadata)
+		expanded = append(expanded, chunk)
+	}
+
+	return expanded, nil
+}
+
+// joinOverlapping appends next to text without repeating the overlap the
+// chunker carried over from the end of one chunk into the next
+func joinOverlapping(text, next string) string {
+	for overlap := min(len(text), len(next)); overlap >= minMergeOverlap; overlap-- {
+		if strings.HasSuffix(text, next[:overlap]) {
+			return text + next[overlap:]
+		}
+	}
+	return text + "\n\n" + next
+}
+
+// spanMetadata is the metadata of the first chunk of a window, extended to the
+// pages, slides and rows of the last one
+func spanMetadata(first, last []byte) []byte {
+	if len(first) == 0 {
+		return first
+	}
+	metadata := ParseChunkMetadata(first)
+	end := ParseChunkMetadata(last)
+
+	metadata.EndPage = max(metadata.EndPage, end.EndPage, end.StartPage)
+	metadata.EndSlideNumber = max(metadata.EndSlideNumber, end.EndSlideNumber, end.SlideNumber)
+	if end.SheetName == metadata.SheetName {
+		metadata.EndRow = max(metadata.EndRow, end.EndRow)
+	}
+
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return first
+	}
+	return raw
+}
@@ -0,0 +1,119 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	MimeTypePNG  = "image/png"
+	MimeTypeJPEG = "image/jpeg"
+	MimeTypeTIFF = "image/tiff"
+)
+
+// image types that can only be read with OCR
+var ocrImageTypes = map[string]bool{
+	MimeTypePNG:  true,
+	MimeTypeJPEG: true,
+	MimeTypeTIFF: true,
+}
+
+// OCREngine reads the text of scanned pages and images
+type OCREngine interface {
+	// RecognizeImage reads the text of a PNG, JPEG or TIFF image
+	RecognizeImage(ctx context.Context, image []byte) (*OCRResult, error)
+	// RecognizePDFPage reads the text of one page of a PDF, pages start at 1
+	RecognizePDFPage(ctx context.Context, pdf []byte, page int) (*OCRResult, error)
+}
+
+// OCRResult is the recognized text with the engine's confidence between 0 and 1
+type OCRResult struct {
+	Text       string
+	Confidence float64
+}
+
+// supports reports whether a file type can be processed, images only when
+// an OCR engine is configured
+func (uc *DocumentUsecase) supports(mimeType string) bool {
+	if uc.ocr != nil && ocrImageTypes[mediaType(mimeType)] {
+		return true
+	}
+	return uc.extractor.Supports(mimeType)
+}
+
+// extractSegments extracts the text of a file. Images and PDF pages without a
+// text layer, like scanned ones, are read with OCR when an engine is configured.
+func (uc *DocumentUsecase) extractSegments(ctx context.Context, documentID, mimeType string, data []byte) ([]Segment, error) {
+	if ocrImageTypes[mediaType(mimeType)] {
+		if uc.ocr == nil {
+			return nil, fmt.Errorf("%w: %s needs an OCR engine", ErrUnsupportedFileType, mimeType)
+		}
+		result, err := uc.ocr.RecognizeImage(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to OCR image: %w", err)
+		}
+		return []Segment{ocrSegment(result, 0)}, nil
+	}
+
+	segments, err := uc.extractor.Extract(mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.ocr == nil || mediaType(mimeType) != MimeTypePDF {
+		return segments, nil
+	}
+	return uc.ocrEmptyPages(ctx, documentID, data, segments)
+}
+
+// ocrEmptyPages replaces the pages the PDF extractor found no text on with
+// their OCR text
+func (uc *DocumentUsecase) ocrEmptyPages(ctx context.Context, documentID string, data []byte, segments []Segment) ([]Segment, error) {
+	numPages, err := pdfPageCount(data)
+	if err != nil {
+		return nil, err
+	}
+
+	byPage := make(map[int]Segment, len(segments))
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) != "" {
+			byPage[segment.PageNumber] = segment
+		}
+	}
+	if len(byPage) == numPages {
+		return segments, nil
+	}
+
+	result := make([]Segment, 0, numPages)
+	recognized := 0
+	for page := 1; page <= numPages; page++ {
+		if segment, ok := byPage[page]; ok {
+			result = append(result, segment)
+			continue
+		}
+
+		ocrResult, err := uc.ocr.RecognizePDFPage(ctx, data, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to OCR page %d: %w", page, err)
+		}
+		if strings.TrimSpace(ocrResult.Text) == "" {
+			continue
+		}
+		result = append(result, ocrSegment(ocrResult, page))
+		recognized++
+	}
+	log.Printf("Recognized %d of %d pages with OCR in document %s", recognized, numPages, documentID)
+
+	return result, nil
+}
+
+func ocrSegment(result *OCRResult, page int) Segment {
+	return Segment{
+		Text:       result.Text,
+		PageNumber: page,
+		OCR:        true,
+		Confidence: result.Confidence,
+	}
+}
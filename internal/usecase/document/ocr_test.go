@@ -0,0 +1,91 @@
+package document_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/document"
+)
+
+// buildPDF writes a PDF with one page per text, pages with an empty text
+// have no text layer at all, like scanned ones
+func buildPDF(pages ...string) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i, text := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		stream := ""
+		if text != "" {
+			stream = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		}
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+func TestPipelineOCRsPagesWithoutText(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{ocr: fake.NewOCREngine()})
+	doc := p.upload(t, "scan.pdf", string(buildPDF("Graf berarah memiliki arah pada setiap sisi.", "")), "application/pdf", "")
+
+	// both short pages end up in one chunk
+	chunks := p.chunks(t, doc)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	content := chunks[0].Content
+	if !strings.Contains(content, "Graf berarah") || !strings.Contains(content, "halaman 2") || strings.Contains(content, "halaman 1") {
+		t.Errorf("got %q, want the text layer of page 1 and the OCR text of page 2 only", content)
+	}
+
+	metadata := document.ParseChunkMetadata(chunks[0].Metadata)
+	if metadata.Source != "ocr" || metadata.Confidence != 0.9 || metadata.StartPage != 1 || metadata.EndPage != 2 {
+		t.Errorf("got pages %d-%d from %s with confidence %v, want pages 1-2 from ocr with 0.9", metadata.StartPage, metadata.EndPage, metadata.Source, metadata.Confidence)
+	}
+}
+
+func TestPipelineOCRsImages(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{ocr: fake.NewOCREngine()})
+	doc := p.upload(t, "papan-tulis.png", "\x89PNG fake image", document.MimeTypePNG, "")
+
+	chunks := p.chunks(t, doc)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	metadata := document.ParseChunkMetadata(chunks[0].Metadata)
+	if metadata.Source != "ocr" || !strings.Contains(chunks[0].Content, "OCR dari gambar") {
+		t.Errorf("got %q from %s, want the OCR text of the image", chunks[0].Content, metadata.Source)
+	}
+}
+
+func TestPipelineRejectsImagesWithoutOCR(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{})
+
+	_, err := p.docs.UploadDocument(t.Context(), p.user.ID, "papan-tulis.png", []byte("\x89PNG fake image"), document.MimeTypePNG, entity.VisibilityPrivate, "")
+	if err == nil {
+		t.Error("UploadDocument of an image succeeded without an OCR engine")
+	}
+}
@@ -0,0 +1,99 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxOOXMLPartSize guards against zip bombs, real document parts are far smaller
+const maxOOXMLPartSize = 64 << 20
+
+// openOOXML opens an Office Open XML package (docx, pptx, xlsx)
+func openOOXML(data []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document package: %w", err)
+	}
+	return zr, nil
+}
+
+// readOOXMLPart returns the content of a part, nil when the package has none
+func readOOXMLPart(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxOOXMLPartSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if len(data) > maxOOXMLPartSize {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+// attr returns the value of the attribute with the local name, ignoring its namespace
+func attr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// ooxmlRelationship is a link from one part of the package to another
+type ooxmlRelationship struct {
+	Type   string
+	Target string
+}
+
+// readRelationships returns the relationships of a part by ID, with targets
+// resolved to part names
+func readRelationships(zr *zip.Reader, partName string) (map[string]ooxmlRelationship, error) {
+	dir, base := path.Split(partName)
+	data, err := readOOXMLPart(zr, dir+"_rels/"+base+".rels")
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Type       string `xml:"Type,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("failed to parse relationships of %s: %w", partName, err)
+	}
+
+	result := make(map[string]ooxmlRelationship, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if rel.TargetMode == "External" {
+			continue
+		}
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(rel.Target, "/") {
+			target = path.Clean(path.Join(dir, rel.Target))
+		}
+		result[rel.ID] = ooxmlRelationship{Type: rel.Type, Target: target}
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ledongthuc/pdf"
+)
+
+type PDFExtractor struct{}
+
+// Extract returns one segment per page that has text
+func (e *PDFExtractor) Extract(data []byte) ([]Segment, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF reader: %w", err)
+	}
+
+	var segments []Segment
+	// Get total pages
+	numPages := reader.NumPage()
+
+	for i := 1; i <= numPages; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, Segment{
+			Text:       text,
+			PageNumber: i,
+		})
+	}
+
+	return segments, nil
+}
+
+// pdfPageCount returns the number of pages of a PDF
+func pdfPageCount(data []byte) (int, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create PDF reader: %w", err)
+	}
+	return reader.NumPage(), nil
+}
@@ -0,0 +1,89 @@
+package document_test
+
+import (
+	"strings"
+	"testing"
+
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/tokenizer"
+)
+
+func TestPDFExtractorNumbersPages(t *testing.T) {
+	pages := []string{"Graf terdiri dari simpul dan sisi.", "", "Basis data menyimpan tabel."}
+	segments, err := (&document.PDFExtractor{}).Extract(buildPDF(pages...))
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	// a page without text keeps its place, so later pages keep their numbers
+	if len(segments) != len(pages) {
+		t.Fatalf("got %d segments, want one per page", len(segments))
+	}
+	for i, segment := range segments {
+		if segment.PageNumber != i+1 || strings.TrimSpace(segment.Text) != pages[i] {
+			t.Errorf("got page %d %q, want page %d %q", segment.PageNumber, segment.Text, i+1, pages[i])
+		}
+	}
+}
+
+func TestChunksOverPageBreaksReportTheirPages(t *testing.T) {
+	bpe, err := tokenizer.ForModel("text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("tokenizer: %v", err)
+	}
+
+	pages := []string{
+		"Graf terdiri dari simpul dan sisi yang menghubungkan pasangan simpul.",
+		"",
+		"Graf berarah memiliki sisi dengan arah dari satu simpul ke simpul lain.",
+		"Basis data relasional menyimpan data dalam tabel berisi baris dan kolom.",
+	}
+	segments, err := (&document.PDFExtractor{}).Extract(buildPDF(pages...))
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	// where the text of every page starts once the pages are joined
+	var joined string
+	var starts, numbers []int
+	for i, page := range pages {
+		if page == "" {
+			continue
+		}
+		if joined != "" {
+			joined += " "
+		}
+		starts = append(starts, len(joined))
+		numbers = append(numbers, i+1)
+		joined += page
+	}
+	pageAt := func(pos int) int {
+		page := 0
+		for i, start := range starts {
+			if start <= pos {
+				page = numbers[i]
+			}
+		}
+		return page
+	}
+
+	chunks := document.NewChunker(bpe, 12, 4, 8191).ChunkSegments(segments)
+	crossing := 0
+	for i, chunk := range chunks {
+		pos := strings.Index(joined, chunk.Content)
+		if pos < 0 {
+			t.Fatalf("chunk %d %q is not part of the text", i, chunk.Content)
+		}
+
+		start, end := pageAt(pos), pageAt(pos+len(chunk.Content)-1)
+		if chunk.First.PageNumber != start || chunk.Last.PageNumber != end {
+			t.Errorf("chunk %d %q: got pages %d-%d, want %d-%d", i, chunk.Content, chunk.First.PageNumber, chunk.Last.PageNumber, start, end)
+		}
+		if start != end {
+			crossing++
+		}
+	}
+	if crossing == 0 {
+		t.Errorf("got no chunk over a page break in %d chunks, want some", len(chunks))
+	}
+}
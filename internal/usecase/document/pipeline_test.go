@@ -0,0 +1,200 @@
+package document_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/adapter/repository/memory"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/tokenizer"
+)
+
+// pipeline wires the document usecase to the fake providers and the memory
+// repositories, so documents go through the whole upload, ingestion and
+// query flow offline
+type pipeline struct {
+	docs      *document.DocumentUsecase
+	docRepo   repository.DocumentRepository
+	chunkRepo repository.ChunkRepository
+	jobRepo   repository.JobRepository
+	user      *entity.User
+}
+
+type pipelineOptions struct {
+	embedder document.EmbeddingService
+	reranker document.Reranker
+	ocr      document.OCREngine
+	chunking document.ChunkingConfig
+	search   document.SearchConfig
+}
+
+func newPipeline(t *testing.T, opts pipelineOptions) *pipeline {
+	t.Helper()
+	db := memory.NewDB()
+
+	user := &entity.User{Email: "budi@kampus.ac.id", Password: "hashed", Name: "Budi", Major: "Informatika", Role: entity.RoleStudent}
+	if err := memory.NewUserRepository(db).Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	bpe, err := tokenizer.ForModel("text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("tokenizer: %v", err)
+	}
+
+	chunking := opts.chunking
+	if chunking.ChunkSize == 0 {
+		chunking = document.ChunkingConfig{ChunkSize: 40, ChunkOverlap: 0, MaxTokens: 8191, Strategy: document.ChunkingFixed}
+	}
+	search := opts.search
+	if search.Mode == "" {
+		search = document.SearchConfig{Mode: document.SearchModeVector, MMRLambda: 1, Expansion: document.ExpansionNone}
+	}
+
+	p := &pipeline{
+		docRepo:   memory.NewDocumentRepository(db),
+		chunkRepo: memory.NewChunkRepository(db),
+		jobRepo:   memory.NewJobRepository(db),
+		user:      user,
+	}
+	var embedder document.EmbeddingService = fake.NewEmbeddingClient(1536)
+	if opts.embedder != nil {
+		embedder = opts.embedder
+	}
+	chat := fake.NewChatClient()
+	// the fake embeddings are less similar than real ones, hence the low threshold
+	p.docs = document.NewDocumentUsecase(p.docRepo, p.chunkRepo, p.jobRepo, memory.NewBlobStore(),
+		embedder, chat, chat, chat, opts.reranker, opts.ocr, bpe, chunking, search, 3, 0.1)
+	return p
+}
+
+// upload stores a document and runs its ingestion job like a worker would
+func (p *pipeline) upload(t *testing.T, filename, content, mimeType, strategy string) *entity.Document {
+	t.Helper()
+	ctx := context.Background()
+
+	doc, err := p.docs.UploadDocument(ctx, p.user.ID, filename, []byte(content), mimeType, entity.VisibilityPrivate, strategy)
+	if err != nil {
+		t.Fatalf("UploadDocument: %v", err)
+	}
+
+	job, err := p.jobRepo.Claim(ctx)
+	if err != nil || job == nil {
+		t.Fatalf("Claim = %v, %v, want the queued job", job, err)
+	}
+	if err := p.docs.ProcessDocument(ctx, job.DocumentID, job.MimeType); err != nil {
+		t.Fatalf("ProcessDocument: %v", err)
+	}
+	if err := p.jobRepo.Complete(ctx, job); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	doc, err = p.docRepo.FindByID(ctx, doc.ID)
+	if err != nil || doc == nil {
+		t.Fatalf("FindByID = %v, %v", doc, err)
+	}
+	return doc
+}
+
+// chunks returns every chunk of the document in order
+func (p *pipeline) chunks(t *testing.T, doc *entity.Document) []entity.DocumentChunk {
+	t.Helper()
+	chunks, err := p.chunkRepo.ListByRanges(context.Background(), doc.UserID, []repository.ChunkRange{{DocumentID: doc.ID, From: 0, To: doc.TotalChunks}})
+	if err != nil {
+		t.Fatalf("ListByRanges: %v", err)
+	}
+	return chunks
+}
+
+func contents(chunks []entity.DocumentChunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Content
+	}
+	return texts
+}
+
+const lectureNotes = `Algoritma Dijkstra mencari lintasan terpendek dari satu simpul sumber ke semua simpul lain pada graf berbobot tidak negatif. Dijkstra selalu memilih simpul dengan jarak sementara terkecil.
+
+Struktur data tumpukan atau stack bekerja dengan prinsip last in first out. Operasi push menambah elemen di puncak dan operasi pop mengambilnya kembali.
+
+Basis data relasional menyimpan data dalam tabel yang saling berelasi melalui kunci primer dan kunci asing. Normalisasi mengurangi redundansi data.`
+
+func TestPipelineUploadProcessQuery(t *testing.T) {
+	ctx := context.Background()
+	p := newPipeline(t, pipelineOptions{})
+
+	doc := p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+	if doc.Status != entity.StatusCompleted || doc.TotalChunks < 3 {
+		t.Fatalf("got status %s with %d chunks, want COMPLETED with one chunk per topic", doc.Status, doc.TotalChunks)
+	}
+
+	result, err := p.docs.QueryDocuments(ctx, p.user.ID, "Bagaimana algoritma Dijkstra mencari lintasan terpendek?", document.QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryDocuments: %v", err)
+	}
+	if len(result.Chunks) == 0 || !strings.Contains(result.Chunks[0].Content, "Dijkstra") {
+		t.Fatalf("got chunks %+v, want the Dijkstra paragraph first", result.Chunks)
+	}
+	if !strings.Contains(result.Answer, "Dijkstra") {
+		t.Errorf("got answer %q, want it to quote the Dijkstra paragraph", result.Answer)
+	}
+
+	_, data, err := p.docs.DownloadDocument(ctx, doc.ID, p.user.ID)
+	if err != nil || string(data) != lectureNotes {
+		t.Errorf("DownloadDocument = %d bytes, %v, want the uploaded file", len(data), err)
+	}
+}
+
+func TestPipelineOnlySearchesVisibleDocuments(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{})
+	p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+	result, err := p.docs.QueryDocuments(context.Background(), "someone-else", "algoritma Dijkstra", document.QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryDocuments: %v", err)
+	}
+	if len(result.Chunks) != 0 || result.Answer != document.NoRelevantInfoAnswer {
+		t.Errorf("another user got %d chunks of a private document", len(result.Chunks))
+	}
+}
+
+func TestPipelineKeepsFilesOfSameNamedUploads(t *testing.T) {
+	ctx := context.Background()
+	p := newPipeline(t, pipelineOptions{})
+
+	// both uploads usually land in the same second, giving them one filename
+	first := p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+	second := p.upload(t, "catatan.txt", "Catatan revisi tentang graf.", "text/plain", "")
+
+	_, data, err := p.docs.DownloadDocument(ctx, first.ID, p.user.ID)
+	if err != nil || string(data) != lectureNotes {
+		t.Fatalf("DownloadDocument of the first upload = %q, %v, want its own file", data, err)
+	}
+
+	if err := p.docs.DeleteDocument(ctx, first.ID, p.user.ID); err != nil {
+		t.Fatalf("DeleteDocument: %v", err)
+	}
+	_, data, err = p.docs.DownloadDocument(ctx, second.ID, p.user.ID)
+	if err != nil || string(data) != "Catatan revisi tentang graf." {
+		t.Errorf("DownloadDocument of the second upload = %q, %v, want its file to survive deleting the first", data, err)
+	}
+}
+
+func TestPipelineReprocessQueuesOnce(t *testing.T) {
+	ctx := context.Background()
+	p := newPipeline(t, pipelineOptions{})
+	doc := p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+	if _, err := p.docs.ReprocessDocument(ctx, doc.ID, p.user.ID); err != nil {
+		t.Fatalf("ReprocessDocument: %v", err)
+	}
+	if _, err := p.docs.ReprocessDocument(ctx, doc.ID, p.user.ID); !errors.Is(err, document.ErrDocumentBusy) {
+		t.Errorf("second ReprocessDocument returned %v, want %v", err, document.ErrDocumentBusy)
+	}
+}
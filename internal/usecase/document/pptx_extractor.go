@@ -0,0 +1,235 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	drawingNamespace  = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	notesRelationship = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+)
+
+// placeholders whose text repeats on every slide and says nothing about it
+var skippedPlaceholders = map[string]bool{
+	"sldNum": true,
+	"dt":     true,
+	"ftr":    true,
+	"hdr":    true,
+	"sldImg": true,
+}
+
+// PPTXExtractor reads the slides of a PowerPoint presentation, in show order,
+// with their speaker notes
+type PPTXExtractor struct{}
+
+// Extract returns one segment per slide that has text
+func (e *PPTXExtractor) Extract(data []byte) ([]Segment, error) {
+	zr, err := openOOXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	slides, err := slideParts(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for i, slide := range slides {
+		content, err := readOOXMLPart(zr, slide)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+
+		text, err := parseSlideText(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse slide %d: %w", i+1, err)
+		}
+
+		notes, err := slideNotes(zr, slide)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notes of slide %d: %w", i+1, err)
+		}
+		if notes != "" {
+			text = strings.TrimSpace(text + "\n\nCatatan pembicara:\n" + notes)
+		}
+
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Text:        text,
+			SlideNumber: i + 1,
+		})
+	}
+
+	return segments, nil
+}
+
+// slideParts lists the slide part names in the order of the presentation
+func slideParts(zr *zip.Reader) ([]string, error) {
+	presentation, err := readOOXMLPart(zr, "ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	if presentation == nil {
+		return nil, fmt.Errorf("not a PowerPoint presentation: ppt/presentation.xml is missing")
+	}
+
+	var doc struct {
+		SlideIDs []struct {
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := xml.Unmarshal(presentation, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse presentation: %w", err)
+	}
+
+	rels, err := readRelationships(zr, "ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var slides []string
+	for _, slideID := range doc.SlideIDs {
+		// r:id, not the numeric id attribute
+		for _, a := range slideID.Attrs {
+			if a.Name.Local == "id" && a.Name.Space != "" {
+				if rel, ok := rels[a.Value]; ok {
+					slides = append(slides, rel.Target)
+				}
+			}
+		}
+	}
+	return slides, nil
+}
+
+// slideNotes returns the speaker notes of a slide, empty when it has none
+func slideNotes(zr *zip.Reader, slide string) (string, error) {
+	rels, err := readRelationships(zr, slide)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels {
+		if rel.Type != notesRelationship {
+			continue
+		}
+		content, err := readOOXMLPart(zr, rel.Target)
+		if err != nil || content == nil {
+			return "", err
+		}
+		return parseSlideText(content)
+	}
+	return "", nil
+}
+
+// parseSlideText collects the text of the shapes and tables of a slide or
+// notes page. Titles are written as markdown headings and table rows as
+// "cell | cell" lines.
+func parseSlideText(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var blocks []string
+	var paragraph strings.Builder
+	var shape []string
+	var row, cell []string
+	var rows []string
+	skipShape, title, inTable, inText := false, false, false, false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			if el.Name.Local == "Fallback" {
+				if err := decoder.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+
+			switch {
+			case el.Name.Local == "sp" && el.Name.Space != drawingNamespace:
+				shape, skipShape, title = nil, false, false
+			case el.Name.Local == "ph" && el.Name.Space != drawingNamespace:
+				placeholder := attr(el, "type")
+				skipShape = skippedPlaceholders[placeholder]
+				title = placeholder == "title" || placeholder == "ctrTitle"
+			case el.Name.Space != drawingNamespace:
+				continue
+			case el.Name.Local == "p":
+				paragraph.Reset()
+			case el.Name.Local == "t":
+				inText = true
+			case el.Name.Local == "br":
+				paragraph.WriteByte('\n')
+			case el.Name.Local == "tbl":
+				inTable, rows = true, nil
+			case el.Name.Local == "tr":
+				row = nil
+			case el.Name.Local == "tc":
+				cell = nil
+			}
+
+		case xml.CharData:
+			if inText {
+				paragraph.Write(el)
+			}
+
+		case xml.EndElement:
+			switch {
+			case el.Name.Local == "sp" && el.Name.Space != drawingNamespace:
+				if !skipShape && len(shape) > 0 {
+					text := strings.Join(shape, "\n")
+					if title {
+						text = "# " + strings.Join(strings.Fields(text), " ")
+					}
+					blocks = append(blocks, text)
+				}
+				shape = nil
+			case el.Name.Space != drawingNamespace:
+				continue
+			case el.Name.Local == "t":
+				inText = false
+			case el.Name.Local == "p":
+				text := strings.TrimSpace(paragraph.String())
+				if text == "" {
+					continue
+				}
+				if inTable {
+					cell = append(cell, text)
+				} else {
+					shape = append(shape, text)
+				}
+			case el.Name.Local == "tc":
+				row = append(row, strings.Join(cell, " "))
+			case el.Name.Local == "tr":
+				if strings.TrimSpace(strings.Join(row, "")) != "" {
+					rows = append(rows, strings.Join(row, " | "))
+				}
+			case el.Name.Local == "tbl":
+				inTable = false
+				if len(rows) > 0 {
+					blocks = append(blocks, strings.Join(rows, "\n"))
+				}
+			}
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
@@ -0,0 +1,93 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+const presentationNS = `xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` +
+	`xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+// slideShape writes a text shape, a placeholder of the given type when it isn't empty
+func slideShape(placeholder string, paragraphs ...string) string {
+	ph := ""
+	if placeholder != "" {
+		ph = `<p:nvPr><p:ph type="` + placeholder + `"/></p:nvPr>`
+	}
+	body := ""
+	for _, paragraph := range paragraphs {
+		body += `<a:p><a:r><a:t>` + paragraph + `</a:t></a:r></a:p>`
+	}
+	return `<p:sp><p:nvSpPr>` + ph + `</p:nvSpPr><p:txBody>` + body + `</p:txBody></p:sp>`
+}
+
+func slidePart(content string) string {
+	return `<p:sld ` + presentationNS + `><p:cSld><p:spTree>` + content + `</p:spTree></p:cSld></p:sld>`
+}
+
+const slideRelationship = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+
+func relationships(relType string, targets map[string]string) string {
+	rels := ""
+	for id, target := range targets {
+		rels += `<Relationship Id="` + id + `" Type="` + relType + `" Target="` + target + `"/>`
+	}
+	return `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels + `</Relationships>`
+}
+
+func TestPPTXExtractorReadsSlidesInShowOrder(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		// the show order differs from the part names
+		"ppt/presentation.xml": `<p:presentation ` + presentationNS + `><p:sldIdLst>` +
+			`<p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId1"/>` +
+			`<p:sldId id="258" r:id="rId2"/><p:sldId id="259" r:id="rId4"/>` +
+			`</p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": relationships(slideRelationship, map[string]string{
+			"rId1": "slides/slide1.xml",
+			"rId2": "slides/slide2.xml",
+			"rId3": "slides/slide3.xml",
+			"rId4": "/ppt/slides/slide4.xml",
+		}),
+		"ppt/slides/slide3.xml": slidePart(slideShape("ctrTitle", "Teori  Graf") +
+			slideShape("body", "Simpul", "Sisi") +
+			slideShape("sldNum", "1") + slideShape("ftr", "Kuliah Matematika Diskrit")),
+		"ppt/slides/slide1.xml": slidePart(slideShape("title", "Derajat") +
+			`<p:graphicFrame><a:graphic><a:graphicData><a:tbl>` +
+			`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Simpul</a:t></a:r></a:p></a:txBody></a:tc>` +
+			`<a:tc><a:txBody><a:p><a:r><a:t>Derajat</a:t></a:r></a:p></a:txBody></a:tc></a:tr>` +
+			`<a:tr><a:tc><a:txBody><a:p><a:r><a:t>A</a:t></a:r></a:p></a:txBody></a:tc>` +
+			`<a:tc><a:txBody><a:p><a:r><a:t>2</a:t></a:r></a:p></a:txBody></a:tc></a:tr>` +
+			`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`),
+		"ppt/slides/_rels/slide1.xml.rels": relationships(notesRelationship, map[string]string{
+			"rId2": "../notesSlides/notesSlide1.xml",
+		}),
+		"ppt/notesSlides/notesSlide1.xml": `<p:notes ` + presentationNS + `><p:cSld><p:spTree>` +
+			slideShape("sldImg") + slideShape("body", "Jelaskan contoh derajat.") + slideShape("sldNum", "2") +
+			`</p:spTree></p:cSld></p:notes>`,
+		// a slide with only a picture has no segment but keeps its number
+		"ppt/slides/slide2.xml": slidePart(`<p:pic/>`),
+		"ppt/slides/slide4.xml": slidePart(slideShape("", "Terima kasih")),
+	})
+
+	segments, err := (&PPTXExtractor{}).Extract(data)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+
+	want := []Segment{
+		{Text: "# Teori Graf\n\nSimpul\nSisi", SlideNumber: 1},
+		{Text: "# Derajat\n\nSimpul | Derajat\nA | 2\n\nCatatan pembicara:\nJelaskan contoh derajat.", SlideNumber: 2},
+		{Text: "Terima kasih", SlideNumber: 4},
+	}
+	if !reflect.DeepEqual(segments, want) {
+		t.Errorf("got segments\n%#v\nwant\n%#v", segments, want)
+	}
+}
+
+func TestPPTXExtractorRequiresPresentation(t *testing.T) {
+	data := buildZip(t, map[string]string{"ppt/slides/slide1.xml": slidePart(slideShape("", "Teks"))})
+	if _, err := (&PPTXExtractor{}).Extract(data); err == nil {
+		t.Error("got no error for a package without ppt/presentation.xml")
+	}
+}
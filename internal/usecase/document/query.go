@@ -0,0 +1,281 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+// NoRelevantInfoAnswer is returned when retrieval finds nothing to answer from
+const NoRelevantInfoAnswer = "Maaf, saya tidak menemukan informasi yang relevan dalam dokumen"
+
+// candidateFactor is how many times topK chunks are retrieved when a reranker
+// or MMR chooses which ones to keep
+const candidateFactor = 4
+
+// QueryFilter restricts which documents a query searches. Empty fields are
+// ignored; access control is always applied on top of it.
+type QueryFilter struct {
+	DocumentIDs []string
+	MimeTypes   []string
+	UploadedBy  string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// QueryOptions are the per-request knobs of the retrieval pipeline
+type QueryOptions struct {
+	Filter QueryFilter
+
+	// History holds the previous conversation turns, oldest first
+	History []entity.Message
+	// CondenseQuery rewrites the query plus History into a standalone search
+	// query before it is embedded. It has no effect without History.
+	CondenseQuery bool
+
+	// SearchMode is vector or hybrid, empty uses the configured mode
+	SearchMode string
+	// VectorWeight and KeywordWeight override the configured fusion weights
+	// of a hybrid search
+	VectorWeight  *float64
+	KeywordWeight *float64
+	// MMRLambda overrides the configured trade-off between relevance and
+	// diversity of the chunks, 1 turns MMR off
+	MMRLambda *float64
+	// NeighbourChunks overrides how many chunks before and after every hit
+	// are added to its context
+	NeighbourChunks *int
+	// Expansion is none, multi-query or hyde, empty uses the configured one
+	Expansion string
+}
+
+// RetrievalResult is what the retrieval pipeline found for a query
+type RetrievalResult struct {
+	// SearchQuery is the text that was actually embedded, which differs from
+	// the user's query when it was condensed
+	SearchQuery string
+	// Expansions are the texts searched besides SearchQuery
+	Expansions []string
+	Chunks     []entity.SimilarChunk
+}
+
+type QueryResult struct {
+	RetrievalResult
+	Answer string
+}
+
+// query document, only searching the user's own documents and public ones
+func (uc *DocumentUsecase) QueryDocuments(
+	ctx context.Context,
+	userID string,
+	query string,
+	opts QueryOptions,
+) (*QueryResult, error) {
+
+	// 1. retrieve similar chunks
+	retrieval, err := uc.RetrieveChunks(ctx, userID, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{RetrievalResult: *retrieval}
+	if len(retrieval.Chunks) == 0 {
+		result.Answer = NoRelevantInfoAnswer
+		return result, nil
+	}
+
+	// 2. generate answer using LLM
+	result.Answer, err = uc.chatService.GenerateAnswer(ctx, query, BuildContext(retrieval.Chunks))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	return result, nil
+}
+
+// QueryStreamHandler receives the stages of a streamed query, in order:
+// the retrieved sources once, then every answer delta
+type QueryStreamHandler struct {
+	OnRetrieved func(result *RetrievalResult) error
+	OnDelta     func(delta string) error
+}
+
+// stream query document, same as QueryDocuments but the answer is streamed.
+// Cancelling ctx stops generation.
+func (uc *DocumentUsecase) StreamQueryDocuments(
+	ctx context.Context,
+	userID string,
+	query string,
+	opts QueryOptions,
+	handler QueryStreamHandler,
+) (*entity.TokenUsage, error) {
+	streamer, ok := uc.chatService.(StreamingChatService)
+	if !ok {
+		return nil, fmt.Errorf("chat service does not support streaming")
+	}
+
+	// 1. retrieve similar chunks
+	retrieval, err := uc.RetrieveChunks(ctx, userID, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := handler.OnRetrieved(retrieval); err != nil {
+		return nil, err
+	}
+
+	if len(retrieval.Chunks) == 0 {
+		return &entity.TokenUsage{}, handler.OnDelta(NoRelevantInfoAnswer)
+	}
+
+	// 2. stream answer from LLM
+	usage, err := streamer.GenerateAnswerStream(ctx, query, BuildContext(retrieval.Chunks), handler.OnDelta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	return usage, nil
+}
+
+// RetrieveChunks embeds the query and its expansions and returns the most
+// similar chunks the user is allowed to see
+func (uc *DocumentUsecase) RetrieveChunks(
+	ctx context.Context,
+	userID string,
+	query string,
+	opts QueryOptions,
+) (*RetrievalResult, error) {
+
+	// 1. condense follow-up question into a standalone query
+	searchQuery := uc.condenseQuery(ctx, query, opts)
+
+	// 2. expand the query into more texts to search for
+	expansion := opts.Expansion
+	if expansion == "" {
+		expansion = uc.search.Expansion
+	}
+	expansions := uc.expandQuery(ctx, searchQuery, expansion)
+
+	// 3. search similar chunks for the query and its expansions. A reranker
+	// and MMR choose from more candidates than are kept.
+	lambda := uc.search.MMRLambda
+	if opts.MMRLambda != nil {
+		lambda = *opts.MMRLambda
+	}
+	topK := uc.topK
+	if uc.reranker != nil || lambda < 1 {
+		topK *= candidateFactor
+	}
+	filter := opts.Filter
+	chunks, err := uc.searchExpanded(ctx, append([]string{searchQuery}, expansions...), repository.ChunkSearchFilter{
+		UserID:      userID,
+		TopK:        topK,
+		Threshold:   uc.threshold,
+		DocumentIDs: filter.DocumentIDs,
+		MimeTypes:   filter.MimeTypes,
+		UploadedBy:  filter.UploadedBy,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. rerank the candidates and keep a diverse topK of them
+	chunks = uc.rerank(ctx, searchQuery, chunks)
+	chunks = selectMMR(chunks, uc.topK, lambda)
+
+	// 5. add the chunks around every hit
+	neighbours := uc.search.NeighbourChunks
+	if opts.NeighbourChunks != nil {
+		neighbours = *opts.NeighbourChunks
+	}
+	chunks, err = uc.expandNeighbours(ctx, userID, chunks, min(neighbours, MaxNeighbourChunks))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RetrievalResult{
+		SearchQuery: searchQuery,
+		Expansions:  expansions,
+		Chunks:      chunks,
+	}, nil
+}
+
+// condenseQuery falls back to the original query when there is nothing to
+// condense or the rewrite fails, so retrieval never breaks because of it
+func (uc *DocumentUsecase) condenseQuery(ctx context.Context, query string, opts QueryOptions) string {
+	if !opts.CondenseQuery || len(opts.History) == 0 || uc.rewriter == nil {
+		return query
+	}
+
+	condensed, err := uc.rewriter.CondenseQuery(ctx, query, opts.History)
+	if err != nil {
+		log.Printf("Failed to condense query, using original: %v", err)
+		return query
+	}
+
+	condensed = strings.TrimSpace(condensed)
+	if condensed == "" {
+		return query
+	}
+	return condensed
+}
+
+// BuildContext formats retrieved chunks into the prompt context, with the
+// pages, slides or rows of every chunk so the model can cite them
+func BuildContext(chunks []entity.SimilarChunk) string {
+	var contextBuilder strings.Builder
+	for i, chunk := range chunks {
+		location := locationLabel(ParseChunkMetadata(chunk.Metadata))
+		if location != "" {
+			location = " - " + location
+		}
+		contextBuilder.WriteString(fmt.Sprintf("[Dokumen %d%s - Similarity: %.2f]\n%s\n\n", i+1, location, chunk.Similarity, chunk.Content))
+	}
+	return contextBuilder.String()
+}
+
+// ParseChunkMetadata decodes the metadata of a chunk, the zero value when it
+// has none
+func ParseChunkMetadata(raw []byte) entity.ChunkMetadata {
+	var metadata entity.ChunkMetadata
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &metadata)
+	}
+	return metadata
+}
+
+// locationLabel ignores pageNumber, which was only an estimate for chunks
+// indexed before real pages were recorded
+func locationLabel(metadata entity.ChunkMetadata) string {
+	var parts []string
+	switch {
+	case metadata.SlideNumber > 0:
+		parts = append(parts, rangeLabel("Slide", metadata.SlideNumber, metadata.EndSlideNumber))
+	case metadata.StartPage > 0:
+		parts = append(parts, rangeLabel("Halaman", metadata.StartPage, metadata.EndPage))
+	}
+	if metadata.SheetName != "" {
+		parts = append(parts, "Sheet "+metadata.SheetName)
+	}
+	if metadata.StartRow > 0 {
+		parts = append(parts, rangeLabel("Baris", metadata.StartRow, metadata.EndRow))
+	}
+	if len(metadata.HeadingPath) > 0 {
+		parts = append(parts, "Bagian: "+strings.Join(metadata.HeadingPath, " > "))
+	}
+	return strings.Join(parts, " - ")
+}
+
+func rangeLabel(unit string, start, end int) string {
+	if end > start {
+		return fmt.Sprintf("%s %d-%d", unit, start, end)
+	}
+	return fmt.Sprintf("%s %d", unit, start)
+}
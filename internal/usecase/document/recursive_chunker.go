@@ -0,0 +1,213 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// boundary is where the recursive chunker may cut text
+type boundary struct {
+	pattern *regexp.Regexp
+	// before cuts in front of a match instead of after it
+	before bool
+}
+
+// boundaries of the recursive chunker, coarsest first
+var recursiveBoundaries = []boundary{
+	// markdown headings, which the extractors write for section titles
+	{pattern: regexp.MustCompile(`(?m)^#{1,6} `), before: true},
+	// paragraphs
+	{pattern: regexp.MustCompile(`\n\n+`)},
+	// sentences and lines
+	{pattern: regexp.MustCompile(`[.!?]["')\]]*( +|\n)|\n`)},
+	// words
+	{pattern: regexp.MustCompile(` +`)},
+}
+
+// RecursiveChunker splits text at the coarsest boundary that yields pieces of
+// at most chunkSize tokens: section headings, then paragraphs, sentences and
+// finally words. Pieces are merged back up to chunkSize, so a chunk only ends
+// mid-paragraph when the paragraph alone is too long.
+type RecursiveChunker struct {
+	chunker *Chunker
+}
+
+// NewRecursiveChunker uses the sizes and the tokenizer of chunker, which also
+// chunks tables
+func NewRecursiveChunker(chunker *Chunker) *RecursiveChunker {
+	return &RecursiveChunker{chunker: chunker}
+}
+
+// Chunk never lets a chunk span two sections or slides
+func (r *RecursiveChunker) Chunk(ctx context.Context, segments []Segment) ([]TextChunk, error) {
+	chunks := []TextChunk{}
+	start := 0
+	for i, segment := range segments {
+		if segment.Table != nil {
+			chunks = append(chunks, r.chunkSection(segments[start:i])...)
+			chunks = append(chunks, r.chunker.chunkTable(segment)...)
+			start = i + 1
+			continue
+		}
+		if i > start && !sameSection(segments[start], segment) {
+			chunks = append(chunks, r.chunkSection(segments[start:i])...)
+			start = i
+		}
+	}
+	return append(chunks, r.chunkSection(segments[start:])...), nil
+}
+
+func sameSection(a, b Segment) bool {
+	return a.SlideNumber == b.SlideNumber && slices.Equal(a.HeadingPath, b.HeadingPath)
+}
+
+func (r *RecursiveChunker) chunkSection(segments []Segment) []TextChunk {
+	joined := joinSegments(segments, normalizeText, "\n\n")
+	if joined.text == "" {
+		return nil
+	}
+
+	var chunks []TextChunk
+	for _, s := range r.split(joined.text, span{start: 0, end: len(joined.text)}, 0) {
+		chunks = append(chunks, r.chunker.limit(joined, s)...)
+	}
+	return chunks
+}
+
+// split returns the chunks of the part of text within s, cut at the
+// boundaries of level or finer
+func (r *RecursiveChunker) split(text string, s span, level int) []span {
+	s = trimSpan(text, s)
+	if s.end <= s.start {
+		return nil
+	}
+	if r.chunker.tokenizer.Count(text[s.start:s.end]) <= r.chunker.chunkSize {
+		return []span{s}
+	}
+
+	// a single word longer than a chunk is cut into token windows
+	if level == len(recursiveBoundaries) {
+		var spans []span
+		for _, part := range r.chunker.split(text[s.start:s.end]) {
+			spans = append(spans, span{start: s.start + part.start, end: s.start + part.end})
+		}
+		return spans
+	}
+
+	pieces := cutAt(text, s, recursiveBoundaries[level])
+	if len(pieces) == 1 {
+		return r.split(text, s, level+1)
+	}
+
+	// merge pieces up to chunkSize, carrying the last ones over into the next
+	// chunk as overlap
+	var spans []span
+	var current []span
+	var tokens []int
+	total := func() int {
+		sum := 0
+		for _, n := range tokens {
+			sum += n
+		}
+		return sum
+	}
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		spans = append(spans, span{start: current[0].start, end: current[len(current)-1].end})
+
+		// at least one piece is dropped, or the next chunk would repeat
+		// this one
+		keep := len(current)
+		for overlap := 0; keep > 1 && overlap+tokens[keep-1] <= r.chunker.chunkOverlap; keep-- {
+			overlap += tokens[keep-1]
+		}
+		current, tokens = current[keep:], tokens[keep:]
+	}
+
+	add := func(piece span, n int) {
+		if total()+n > r.chunker.chunkSize {
+			flush()
+			// drop overlap that would leave no room for the piece
+			for len(current) > 0 && total()+n > r.chunker.chunkSize {
+				current, tokens = current[1:], tokens[1:]
+			}
+		}
+		current = append(current, piece)
+		tokens = append(tokens, n)
+	}
+
+	for _, piece := range pieces {
+		n := r.chunker.tokenizer.Count(text[piece.start:piece.end])
+		if n <= r.chunker.chunkSize {
+			add(piece, n)
+			continue
+		}
+		// too long on its own, split it at the next boundary. A short
+		// heading before it still joins its first part.
+		for _, part := range r.split(text, piece, level+1) {
+			add(part, r.chunker.tokenizer.Count(text[part.start:part.end]))
+		}
+	}
+	flush()
+
+	for i := range spans {
+		spans[i] = trimSpan(text, spans[i])
+	}
+	return spans
+}
+
+// cutAt splits the part of text within s at every match of b
+func cutAt(text string, s span, b boundary) []span {
+	var pieces []span
+	start := s.start
+	for _, m := range b.pattern.FindAllStringIndex(text[s.start:s.end], -1) {
+		cut := s.start + m[1]
+		if b.before {
+			cut = s.start + m[0]
+		}
+		if cut <= start || cut >= s.end {
+			continue
+		}
+		pieces = append(pieces, span{start: start, end: cut})
+		start = cut
+	}
+	return append(pieces, span{start: start, end: s.end})
+}
+
+// trimSpan moves the ends of s inwards past spaces and line breaks
+func trimSpan(text string, s span) span {
+	for s.start < s.end && (text[s.start] == ' ' || text[s.start] == '\n') {
+		s.start++
+	}
+	for s.end > s.start && (text[s.end-1] == ' ' || text[s.end-1] == '\n') {
+		s.end--
+	}
+	return s
+}
+
+// normalizeText collapses whitespace like cleanText but keeps line breaks and
+// a single blank line between paragraphs
+func normalizeText(text string) string {
+	var result strings.Builder
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(cleanText(line))
+		if line == "" {
+			blank = result.Len() > 0
+			continue
+		}
+		if result.Len() > 0 {
+			result.WriteByte('\n')
+			if blank {
+				result.WriteByte('\n')
+			}
+		}
+		result.WriteString(line)
+		blank = false
+	}
+	return result.String()
+}
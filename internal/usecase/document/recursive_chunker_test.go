@@ -0,0 +1,128 @@
+package document_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/tokenizer"
+)
+
+func newRecursiveChunker(t *testing.T, chunkSize int) (*document.RecursiveChunker, *tokenizer.BPE) {
+	t.Helper()
+	bpe, err := tokenizer.ForModel("text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("tokenizer: %v", err)
+	}
+	return document.NewRecursiveChunker(document.NewChunker(bpe, chunkSize, 0, 8191)), bpe
+}
+
+func recursiveChunks(t *testing.T, chunker *document.RecursiveChunker, text string) []string {
+	t.Helper()
+	chunks, err := chunker.Chunk(context.Background(), []document.Segment{{Text: text}})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+	contents := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		contents[i] = chunk.Content
+	}
+	return contents
+}
+
+func TestRecursiveChunkerCutsAtTheCoarsestBoundary(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		text      string
+		want      []string
+	}{
+		{
+			// cut at paragraphs, the short first section would take the
+			// first paragraph of the next
+			name:      "headings",
+			chunkSize: 30,
+			text: "# Graf\n\nGraf terdiri dari simpul dan sisi.\n\n" +
+				"# Basis Data\n\nBasis data menyimpan tabel.\n\nTabel berisi baris dan kolom.",
+			want: []string{
+				"# Graf\n\nGraf terdiri dari simpul dan sisi.",
+				"# Basis Data\n\nBasis data menyimpan tabel.\n\nTabel berisi baris dan kolom.",
+			},
+		},
+		{
+			// cut at sentences, the first paragraph would take the first
+			// sentence of the second
+			name:      "paragraphs",
+			chunkSize: 28,
+			text: "Graf berarah memiliki arah pada setiap sisi.\n\n" +
+				"Graf terdiri dari simpul dan sisi. Sisi menghubungkan dua simpul.",
+			want: []string{
+				"Graf berarah memiliki arah pada setiap sisi.",
+				"Graf terdiri dari simpul dan sisi. Sisi menghubungkan dua simpul.",
+			},
+		},
+		{
+			// cut at words, the first chunk would run into the second sentence
+			name:      "sentences",
+			chunkSize: 26,
+			text: "Graf berarah memiliki arah pada setiap sisi. " +
+				"Derajat simpul adalah banyaknya sisi yang bersisian. Sisi menghubungkan dua simpul.",
+			want: []string{
+				"Graf berarah memiliki arah pada setiap sisi.",
+				"Derajat simpul adalah banyaknya sisi yang bersisian. Sisi menghubungkan dua simpul.",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		chunker, _ := newRecursiveChunker(t, test.chunkSize)
+		if got := recursiveChunks(t, chunker, test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got chunks %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecursiveChunkerFallsBackToWordsAndTokens(t *testing.T) {
+	const chunkSize = 8
+	chunker, bpe := newRecursiveChunker(t, chunkSize)
+
+	words := strings.Fields(strings.Repeat("graf berarah memiliki arah pada setiap sisi ", 4))
+	// a single word longer than a chunk, like a URL or a hash
+	long := strings.Repeat("a1b2c3d4e5", 12)
+
+	tests := []struct {
+		name string
+		text string
+		// whole is whether every chunk holds whole words only
+		whole bool
+	}{
+		{"sentence without breaks", strings.Join(words, " "), true},
+		{"word longer than a chunk", "awal " + long + " akhir", false},
+	}
+
+	for _, test := range tests {
+		chunks := recursiveChunks(t, chunker, test.text)
+		if len(chunks) < 2 {
+			t.Fatalf("%s: got %d chunks, want the text split", test.name, len(chunks))
+		}
+
+		for i, chunk := range chunks {
+			if tokens := bpe.Count(chunk); tokens > chunkSize {
+				t.Errorf("%s: chunk %d %q has %d tokens", test.name, i, chunk, tokens)
+			}
+			if test.whole && !strings.Contains(" "+test.text+" ", " "+chunk+" ") {
+				t.Errorf("%s: chunk %d %q cuts a word", test.name, i, chunk)
+			}
+		}
+
+		// without overlap the chunks add up to the text
+		if got, want := strings.Join(strings.Fields(strings.Join(chunks, "")), ""), strings.Join(strings.Fields(test.text), ""); got != want {
+			t.Errorf("%s: got chunks %q, want them to add up to the text", test.name, chunks)
+		}
+		if !test.whole && !strings.Contains(long, chunks[1]) {
+			t.Errorf("%s: got chunks %q, want the long word cut into token windows", test.name, chunks)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"rag-api/internal/domain/entity"
+)
+
+var (
+	ErrDocumentNotFound     = errors.New("document not found")
+	ErrDocumentBusy         = errors.New("document is already queued for processing")
+	ErrOriginalFileNotFound = errors.New("original file of the document is not available")
+)
+
+// ReindexResult counts what a bulk reindex did
+type ReindexResult struct {
+	Queued  int
+	Skipped int
+}
+
+// ReprocessDocument queues the user's document to be processed again from its
+// stored original, with the current chunking and embedding settings. A
+// completed document stays searchable with its old chunks until the new ones
+// are swapped in.
+func (uc *DocumentUsecase) ReprocessDocument(
+	ctx context.Context,
+	documentID string,
+	userID string,
+) (*entity.Document, error) {
+	doc, err := uc.docRepo.FindByIDAndUserID(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	if err := uc.queueReprocess(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// ReindexAll queues every document that has a stored original and is not
+// queued already. Meant for admins after changing the chunking or embedding
+// settings.
+func (uc *DocumentUsecase) ReindexAll(ctx context.Context) (*ReindexResult, error) {
+	docs, err := uc.docRepo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReindexResult{}
+	for i := range docs {
+		err := uc.queueReprocess(ctx, &docs[i])
+		if errors.Is(err, ErrDocumentBusy) || errors.Is(err, ErrOriginalFileNotFound) {
+			log.Printf("Skipping reindex of document %s: %v", docs[i].ID, err)
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to queue document %s: %w", docs[i].ID, err)
+		}
+		result.Queued++
+	}
+
+	log.Printf("Queued %d documents for reindexing, skipped %d", result.Queued, result.Skipped)
+	return result, nil
+}
+
+func (uc *DocumentUsecase) queueReprocess(ctx context.Context, doc *entity.Document) error {
+	exists, err := uc.blobStore.Exists(ctx, blobKey(doc))
+	if err != nil {
+		return fmt.Errorf("failed to check file: %w", err)
+	}
+	if !exists {
+		return ErrOriginalFileNotFound
+	}
+
+	// the job reads the file from blob storage when it runs
+	queued, err := uc.jobRepo.EnqueueIfIdle(ctx, &entity.IngestionJob{
+		DocumentID: doc.ID,
+		MimeType:   doc.MimeType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue document for processing: %w", err)
+	}
+	if !queued {
+		return ErrDocumentBusy
+	}
+
+	// a completed document keeps serving its old chunks meanwhile
+	if doc.Status != entity.StatusCompleted {
+		if err := uc.docRepo.UpdateStatus(ctx, doc.ID, entity.StatusProcessing); err != nil {
+			return err
+		}
+		doc.Status = entity.StatusProcessing
+	}
+
+	return nil
+}
+
+// loadOriginal reads the stored original file of a document
+func (uc *DocumentUsecase) loadOriginal(ctx context.Context, doc *entity.Document) ([]byte, error) {
+	data, err := uc.blobStore.Get(ctx, blobKey(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if data == nil {
+		return nil, ErrOriginalFileNotFound
+	}
+	return data, nil
+}
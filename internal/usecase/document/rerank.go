@@ -0,0 +1,57 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"rag-api/internal/domain/entity"
+)
+
+// Reranker scores how well each document answers the query, more closely
+// than embedding similarity can, e.g. with a cross-encoder or an LLM
+type Reranker interface {
+	// Rerank returns one score per document, in the same order. Higher is
+	// more relevant; the range depends on the reranker.
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// rerank orders the candidates by their reranker score. When reranking fails
+// the retrieval order is kept, so retrieval never breaks because of it.
+func (uc *DocumentUsecase) rerank(ctx context.Context, query string, chunks []entity.SimilarChunk) []entity.SimilarChunk {
+	if uc.reranker == nil || len(chunks) == 0 {
+		return chunks
+	}
+
+	scores, err := uc.rerankScores(ctx, query, chunks)
+	if err != nil {
+		log.Printf("Failed to rerank chunks, using retrieval order: %v", err)
+		return chunks
+	}
+
+	for i := range chunks {
+		chunks[i].RerankScore = &scores[i]
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return *chunks[i].RerankScore > *chunks[j].RerankScore
+	})
+
+	return chunks
+}
+
+func (uc *DocumentUsecase) rerankScores(ctx context.Context, query string, chunks []entity.SimilarChunk) ([]float64, error) {
+	documents := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		documents[i] = chunk.Content
+	}
+
+	scores, err := uc.reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(chunks) {
+		return nil, fmt.Errorf("got %d rerank scores, expected %d", len(scores), len(chunks))
+	}
+	return scores, nil
+}
@@ -0,0 +1,90 @@
+package document_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"rag-api/internal/adapter/fake"
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/usecase/document"
+)
+
+// rerankerFunc scores the documents with a function
+type rerankerFunc func(query string, documents []string) ([]float64, error)
+
+func (f rerankerFunc) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	return f(query, documents)
+}
+
+func retrieve(t *testing.T, p *pipeline, query string) []entity.SimilarChunk {
+	t.Helper()
+	result, err := p.docs.RetrieveChunks(context.Background(), p.user.ID, query, document.QueryOptions{})
+	if err != nil {
+		t.Fatalf("RetrieveChunks: %v", err)
+	}
+	return result.Chunks
+}
+
+func TestRerankingOrdersByRerankScore(t *testing.T) {
+	// the reranker prefers exactly the chunks retrieval ranks lowest
+	var candidates int
+	p := newPipeline(t, pipelineOptions{reranker: rerankerFunc(func(query string, documents []string) ([]float64, error) {
+		candidates = len(documents)
+		scores := make([]float64, len(documents))
+		for i := range documents {
+			scores[i] = float64(i)
+		}
+		return scores, nil
+	})})
+	p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+	chunks := retrieve(t, p, "algoritma Dijkstra pada graf")
+	if candidates < 2 || len(chunks) != min(candidates, 3) {
+		t.Fatalf("reranked %d candidates into %d chunks, want at least 2 candidates and up to 3 chunks", candidates, len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.RerankScore == nil || *chunk.RerankScore != float64(candidates-1-i) {
+			t.Fatalf("chunk %d has rerank score %v, want the reversed retrieval order", i, chunk.RerankScore)
+		}
+	}
+	if last := chunks[len(chunks)-1]; chunks[0].Similarity > last.Similarity {
+		t.Errorf("got similarities %v before %v, want the least similar chunk first", chunks[0].Similarity, last.Similarity)
+	}
+}
+
+func TestRerankingWithFakeReranker(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{reranker: fake.NewReranker()})
+	p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+	chunks := retrieve(t, p, "kunci primer dan kunci asing tabel")
+	if len(chunks) == 0 || !strings.Contains(chunks[0].Content, "kunci primer") {
+		t.Fatalf("got chunks %+v, want the database paragraph first", chunks)
+	}
+	for i := 1; i < len(chunks); i++ {
+		if *chunks[i].RerankScore > *chunks[i-1].RerankScore {
+			t.Errorf("got rerank scores %v after %v, want them descending", *chunks[i].RerankScore, *chunks[i-1].RerankScore)
+		}
+	}
+}
+
+func TestRerankingFailureKeepsRetrievalOrder(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{reranker: rerankerFunc(func(query string, documents []string) ([]float64, error) {
+		return nil, errors.New("reranker unavailable")
+	})})
+	p.upload(t, "catatan.txt", lectureNotes, "text/plain", "")
+
+	chunks := retrieve(t, p, "algoritma Dijkstra pada graf")
+	if len(chunks) == 0 || !strings.Contains(chunks[0].Content, "Dijkstra") {
+		t.Fatalf("got chunks %+v, want the retrieval order with Dijkstra first", chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk.RerankScore != nil {
+			t.Errorf("chunk %d has a rerank score after reranking failed", i)
+		}
+		if i > 0 && chunk.Similarity > chunks[i-1].Similarity {
+			t.Errorf("got similarity %v after %v, want the retrieval order", chunk.Similarity, chunks[i-1].Similarity)
+		}
+	}
+}
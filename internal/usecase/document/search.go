@@ -0,0 +1,87 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// names of the search modes
+const (
+	// SearchModeVector ranks chunks by embedding similarity only
+	SearchModeVector = "vector"
+	// SearchModeHybrid also matches the words of the query, which finds exact
+	// terms like course codes and acronyms that embeddings miss
+	SearchModeHybrid = "hybrid"
+)
+
+// hybridCandidates is how many times topK chunks of each ranking are fused
+const hybridCandidates = 5
+
+var ErrUnknownSearchMode = errors.New("unknown search mode")
+
+// SearchConfig is the default retrieval of queries that don't choose their own
+type SearchConfig struct {
+	Mode string
+	// weights of the vector and keyword rankings in a hybrid search
+	VectorWeight  float64
+	KeywordWeight float64
+	// RRFK damps the difference between the top ranks, 60 is the usual value
+	RRFK int
+	// MMRLambda weighs relevance against diversity when picking the topK
+	// chunks, between 0 and 1. 1 keeps the most relevant ones.
+	MMRLambda float64
+	// NeighbourChunks is how many chunks before and after every hit are added
+	// to its context, at most 5
+	NeighbourChunks int
+	// Expansion is the query expansion strategy: none, multi-query or hyde
+	Expansion string
+	// MultiQueryCount is how many paraphrases multi-query searches
+	MultiQueryCount int
+}
+
+// SupportsSearchMode reports whether mode is a known search mode
+func (uc *DocumentUsecase) SupportsSearchMode(mode string) bool {
+	return mode == SearchModeVector || mode == SearchModeHybrid
+}
+
+// searchChunks runs the search mode chosen in opts, or the configured one
+func (uc *DocumentUsecase) searchChunks(
+	ctx context.Context,
+	embedding pgvector.Vector,
+	query string,
+	filter repository.ChunkSearchFilter,
+	opts QueryOptions,
+) ([]entity.SimilarChunk, error) {
+	mode := opts.SearchMode
+	if mode == "" {
+		mode = uc.search.Mode
+	}
+
+	switch mode {
+	case SearchModeVector:
+		return uc.chunkRepo.SearchSimilar(ctx, embedding, filter)
+	case SearchModeHybrid:
+		search := repository.HybridSearch{
+			Query:         query,
+			VectorWeight:  uc.search.VectorWeight,
+			KeywordWeight: uc.search.KeywordWeight,
+			RRFK:          uc.search.RRFK,
+			Candidates:    filter.TopK * hybridCandidates,
+		}
+		if opts.VectorWeight != nil {
+			search.VectorWeight = *opts.VectorWeight
+		}
+		if opts.KeywordWeight != nil {
+			search.KeywordWeight = *opts.KeywordWeight
+		}
+		return uc.chunkRepo.SearchHybrid(ctx, embedding, search, filter)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSearchMode, mode)
+	}
+}
@@ -0,0 +1,63 @@
+package document
+
+import "strings"
+
+// sectionBuilder splits structured text into one segment per heading section.
+// Every segment carries the path of headings above it, and headings are kept
+// in the text as markdown headings.
+type sectionBuilder struct {
+	headings []string
+	levels   []int
+	blocks   []string
+	hasBody  bool
+	segments []Segment
+}
+
+// heading starts a new section below the open headings of a lower level
+func (b *sectionBuilder) heading(level int, text string) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return
+	}
+
+	// a heading directly followed by a subheading shares its section
+	if b.hasBody {
+		b.flush()
+	}
+
+	for len(b.levels) > 0 && b.levels[len(b.levels)-1] >= level {
+		b.levels = b.levels[:len(b.levels)-1]
+		b.headings = b.headings[:len(b.headings)-1]
+	}
+	b.levels = append(b.levels, level)
+	b.headings = append(b.headings, text)
+
+	b.blocks = append(b.blocks, strings.Repeat("#", level)+" "+text)
+}
+
+// block adds a paragraph, list item or table to the current section
+func (b *sectionBuilder) block(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	b.blocks = append(b.blocks, text)
+	b.hasBody = true
+}
+
+func (b *sectionBuilder) flush() {
+	if len(b.blocks) > 0 {
+		b.segments = append(b.segments, Segment{
+			Text:        strings.Join(b.blocks, "\n\n"),
+			HeadingPath: append([]string(nil), b.headings...),
+		})
+	}
+	b.blocks = nil
+	b.hasBody = false
+}
+
+// result returns the segments, including the section still open
+func (b *sectionBuilder) result() []Segment {
+	b.flush()
+	return b.segments
+}
@@ -0,0 +1,202 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+const (
+	// semanticBatchSize is how many sentences are embedded per request
+	semanticBatchSize = 256
+	// semanticWindow is how many more sentences on either side of a gap are
+	// compared, which evens out the noise of single short sentences
+	semanticWindow = 1
+)
+
+// SemanticChunker cuts text where the topic changes: between consecutive
+// sentences whose embeddings are unusually far apart. Chunks hold at least
+// minTokens and at most chunkSize tokens; they don't overlap, as their
+// boundaries are meant to be topic boundaries.
+type SemanticChunker struct {
+	recursive  *RecursiveChunker
+	embedder   EmbeddingService
+	minTokens  int
+	percentile float64
+}
+
+// NewSemanticChunker cuts where the distance between two sentences is above
+// the given percentile of all distances in the section
+func NewSemanticChunker(chunker *Chunker, embedder EmbeddingService, minTokens int, percentile float64) *SemanticChunker {
+	return &SemanticChunker{
+		recursive:  NewRecursiveChunker(chunker),
+		embedder:   embedder,
+		minTokens:  minTokens,
+		percentile: percentile,
+	}
+}
+
+// Chunk never lets a chunk span two sections or slides
+func (c *SemanticChunker) Chunk(ctx context.Context, segments []Segment) ([]TextChunk, error) {
+	chunks := []TextChunk{}
+	start := 0
+	flush := func(end int) error {
+		sectionChunks, err := c.chunkSection(ctx, segments[start:end])
+		chunks = append(chunks, sectionChunks...)
+		return err
+	}
+
+	for i, segment := range segments {
+		if segment.Table != nil {
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, c.recursive.chunker.chunkTable(segment)...)
+			start = i + 1
+			continue
+		}
+		if i > start && !sameSection(segments[start], segment) {
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+			start = i
+		}
+	}
+	if err := flush(len(segments)); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+func (c *SemanticChunker) chunkSection(ctx context.Context, segments []Segment) ([]TextChunk, error) {
+	joined := joinSegments(segments, normalizeText, "\n\n")
+	if joined.text == "" {
+		return nil, nil
+	}
+	text := joined.text
+	chunker := c.recursive.chunker
+
+	// 1. split into sentences, cutting those longer than a chunk at words
+	var sentences []span
+	for _, sentence := range cutAt(text, span{start: 0, end: len(text)}, recursiveBoundaries[2]) {
+		sentences = append(sentences, c.recursive.split(text, sentence, 3)...)
+	}
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+
+	contents := make([]string, len(sentences))
+	tokens := make([]int, len(sentences))
+	for i, sentence := range sentences {
+		contents[i] = text[sentence.start:sentence.end]
+		tokens[i] = chunker.tokenizer.Count(contents[i])
+	}
+
+	// 2. distance between every sentence and the next
+	distances, err := c.distances(ctx, contents)
+	if err != nil {
+		return nil, err
+	}
+	breakpoint := percentile(distances, c.percentile)
+
+	// 3. cut at distance spikes once a chunk is big enough, and wherever the
+	// next sentence wouldn't fit
+	var chunks []TextChunk
+	first, total := 0, 0
+	for i := range sentences {
+		if i > first && (total+tokens[i] > chunker.chunkSize ||
+			(total >= c.minTokens && distances[i-1] > breakpoint)) {
+			chunks = append(chunks, chunker.limit(joined, span{start: sentences[first].start, end: sentences[i-1].end})...)
+			first, total = i, 0
+		}
+		total += tokens[i]
+	}
+	chunks = append(chunks, chunker.limit(joined, span{start: sentences[first].start, end: sentences[len(sentences)-1].end})...)
+
+	return chunks, nil
+}
+
+// distances returns the cosine distance between the text before and after
+// every gap between two sentences, each side holding up to semanticWindow
+// more sentences
+func (c *SemanticChunker) distances(ctx context.Context, sentences []string) ([]float64, error) {
+	if len(sentences) < 2 {
+		return nil, nil
+	}
+
+	// with a window of one, the text after a gap is also the text before the
+	// gap two sentences later, so every distinct text is embedded once
+	gaps := len(sentences) - 1
+	var windows []string
+	index := make(map[string]int)
+	windowOf := func(text string) int {
+		if i, ok := index[text]; ok {
+			return i
+		}
+		index[text] = len(windows)
+		windows = append(windows, text)
+		return len(windows) - 1
+	}
+	sides := make([][2]int, gaps)
+	for i := 0; i < gaps; i++ {
+		before := sentences[max(i-semanticWindow, 0) : i+1]
+		after := sentences[i+1 : min(i+2+semanticWindow, len(sentences))]
+		sides[i] = [2]int{windowOf(strings.Join(before, " ")), windowOf(strings.Join(after, " "))}
+	}
+
+	var embeddings []pgvector.Vector
+	for start := 0; start < len(windows); start += semanticBatchSize {
+		end := min(start+semanticBatchSize, len(windows))
+		batch, err := c.embedder.GenerateBatchEmbeddings(ctx, windows[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed sentences: %w", err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("got %d sentence embeddings, expected %d", len(batch), end-start)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+
+	distances := make([]float64, gaps)
+	for i, side := range sides {
+		distances[i] = 1 - cosineSimilarity(embeddings[side[0]].Slice(), embeddings[side[1]].Slice())
+	}
+	return distances, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// percentile returns the p-th percentile of values, interpolating between
+// the nearest ranks. Without values nothing is above it.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.Inf(1)
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	if lower < 0 {
+		return sorted[0]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
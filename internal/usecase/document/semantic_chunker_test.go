@@ -0,0 +1,79 @@
+package document_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"rag-api/internal/usecase/document"
+	"rag-api/pkg/tokenizer"
+)
+
+// twoTopics switches from graphs to databases halfway, within one paragraph
+const twoTopics = "Graf terdiri dari simpul dan sisi yang menghubungkan simpul. " +
+	"Graf berarah memiliki sisi dengan arah dari satu simpul ke simpul lain. " +
+	"Derajat simpul pada graf adalah banyaknya sisi yang bersisian dengan simpul itu. " +
+	"Lintasan pada graf adalah barisan simpul yang dihubungkan oleh sisi. " +
+	"Basis data relasional menyimpan data dalam tabel berisi baris dan kolom. " +
+	"Kunci primer tabel mengidentifikasi setiap baris data secara unik. " +
+	"Kunci asing menghubungkan baris data sebuah tabel dengan tabel lain. " +
+	"Normalisasi tabel basis data mengurangi redundansi data."
+
+func TestPipelineSemanticChunkingCutsAtTopicChange(t *testing.T) {
+	p := newPipeline(t, pipelineOptions{chunking: document.ChunkingConfig{
+		ChunkSize:                    200,
+		MaxTokens:                    8191,
+		Strategy:                     document.ChunkingFixed,
+		SemanticMinTokens:            20,
+		SemanticBreakpointPercentile: 80,
+	}})
+
+	// the whole text fits one fixed chunk
+	fixed := p.chunks(t, p.upload(t, "catatan.txt", twoTopics, "text/plain", document.ChunkingFixed))
+	if len(fixed) != 1 {
+		t.Fatalf("got %d fixed chunks, want the whole text in 1", len(fixed))
+	}
+
+	chunks := p.chunks(t, p.upload(t, "catatan.txt", twoTopics, "text/plain", document.ChunkingSemantic))
+	if len(chunks) != 2 {
+		t.Fatalf("got %d semantic chunks %q, want one per topic", len(chunks), contents(chunks))
+	}
+	if !strings.HasPrefix(chunks[0].Content, "Graf terdiri") || !strings.HasSuffix(chunks[0].Content, "dihubungkan oleh sisi.") {
+		t.Errorf("got first chunk %q, want the four sentences about graphs", chunks[0].Content)
+	}
+	if !strings.HasPrefix(chunks[1].Content, "Basis data relasional") {
+		t.Errorf("got second chunk %q, want the sentences about databases", chunks[1].Content)
+	}
+}
+
+func TestSemanticChunkerEmbedsEveryWindowOnce(t *testing.T) {
+	bpe, err := tokenizer.ForModel("text-embedding-3-small")
+	if err != nil {
+		t.Fatalf("tokenizer: %v", err)
+	}
+	embedder := newRecordingEmbedder()
+	chunker := document.NewSemanticChunker(document.NewChunker(bpe, 200, 0, 8191), embedder, 20, 80)
+
+	chunks, err := chunker.Chunk(context.Background(), []document.Segment{{Text: twoTopics}})
+	if err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want one per topic", len(chunks))
+	}
+
+	seen := make(map[string]bool)
+	for _, batch := range embedder.batches {
+		for _, text := range batch {
+			if seen[text] {
+				t.Errorf("embedded %q more than once", text)
+			}
+			seen[text] = true
+		}
+	}
+	// the 7 gaps between the 8 sentences have 14 sides, 5 of which are the
+	// side before a gap two sentences later
+	if len(seen) != 9 {
+		t.Errorf("embedded %d windows, want the 9 distinct ones", len(seen))
+	}
+}
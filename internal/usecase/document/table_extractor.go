@@ -0,0 +1,494 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVExtractor reads a CSV file as one table. The delimiter is detected from
+// the header line, since spreadsheets in many locales export with ';'.
+type CSVExtractor struct{}
+
+func (e *CSVExtractor) Extract(data []byte) ([]Segment, error) {
+	text := decodeText(data)
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectDelimiter(text)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows [][]string
+	var rowNumbers []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		// the line the record starts on, the reader skips blank lines
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, record)
+		rowNumbers = append(rowNumbers, line)
+	}
+
+	table := newTable(rows, rowNumbers)
+	if table == nil {
+		return nil, nil
+	}
+	return []Segment{{Table: table}}, nil
+}
+
+// detectDelimiter picks the most frequent of the usual delimiters in the first line
+func detectDelimiter(text string) rune {
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	delimiter, best := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if count := strings.Count(firstLine, string(candidate)); count > best {
+			delimiter, best = candidate, count
+		}
+	}
+	return delimiter
+}
+
+// XLSXExtractor reads every sheet of an Excel workbook as a table
+type XLSXExtractor struct{}
+
+func (e *XLSXExtractor) Extract(data []byte) ([]Segment, error) {
+	zr, err := openOOXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	workbook, err := readOOXMLPart(zr, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	if workbook == nil {
+		return nil, fmt.Errorf("not an Excel workbook: xl/workbook.xml is missing")
+	}
+
+	var doc struct {
+		Properties struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name  string     `xml:"name,attr"`
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(workbook, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse workbook: %w", err)
+	}
+
+	rels, err := readRelationships(zr, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sharedStrings, err := readSharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	styles, err := readCellStyles(zr)
+	if err != nil {
+		return nil, err
+	}
+	styles.date1904 = doc.Properties.Date1904 == "1" || doc.Properties.Date1904 == "true"
+
+	var segments []Segment
+	for _, sheet := range doc.Sheets {
+		// r:id, the relationship to the worksheet part
+		var part string
+		for _, a := range sheet.Attrs {
+			if a.Name.Local == "id" && a.Name.Space != "" {
+				part = rels[a.Value].Target
+			}
+		}
+		if part == "" {
+			continue
+		}
+
+		content, err := readOOXMLPart(zr, part)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+
+		rows, rowNumbers, err := parseWorksheet(content, sharedStrings, styles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sheet %s: %w", sheet.Name, err)
+		}
+
+		if table := newTable(rows, rowNumbers); table != nil {
+			segments = append(segments, Segment{
+				SheetName: sheet.Name,
+				Table:     table,
+			})
+		}
+	}
+
+	return segments, nil
+}
+
+// readSharedStrings returns the shared string table cells refer to by index
+func readSharedStrings(zr *zip.Reader) ([]string, error) {
+	data, err := readOOXMLPart(zr, "xl/sharedStrings.xml")
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stringsTable []string
+	var current strings.Builder
+	inText, inPhonetic := false, false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse shared strings: %w", err)
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// phonetic hints of east asian text repeat the string
+				inPhonetic = true
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				current.Write(el)
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "si":
+				stringsTable = append(stringsTable, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		}
+	}
+
+	return stringsTable, nil
+}
+
+// cellStyles tells which cells hold dates or times. Excel stores them as
+// numbers, only the number format of the cell's style makes them dates.
+type cellStyles struct {
+	// layouts holds the time layout of every cell style, "" for styles that
+	// aren't dates
+	layouts []string
+	// date1904 workbooks count days from 1904 instead of 1900
+	date1904 bool
+}
+
+// layout returns the time layout of the style index of a cell, "" when the
+// cell isn't a date
+func (s cellStyles) layout(style string) string {
+	i, err := strconv.Atoi(style)
+	if err != nil || i < 0 || i >= len(s.layouts) {
+		return ""
+	}
+	return s.layouts[i]
+}
+
+// readCellStyles reads the number format of every cell style
+func readCellStyles(zr *zip.Reader) (cellStyles, error) {
+	data, err := readOOXMLPart(zr, "xl/styles.xml")
+	if err != nil || data == nil {
+		return cellStyles{}, err
+	}
+
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := xml.Unmarshal(data, &styles); err != nil {
+		return cellStyles{}, fmt.Errorf("failed to parse styles: %w", err)
+	}
+
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, numFmt := range styles.NumFmts {
+		custom[numFmt.ID] = numFmt.Code
+	}
+
+	result := cellStyles{layouts: make([]string, len(styles.CellXfs))}
+	for i, xf := range styles.CellXfs {
+		if code, ok := custom[xf.NumFmtID]; ok {
+			result.layouts[i] = dateLayout(code)
+		} else {
+			result.layouts[i] = builtinDateLayout(xf.NumFmtID)
+		}
+	}
+	return result, nil
+}
+
+// builtinDateLayout returns the layout of the built-in number formats that
+// are dates or times, including the east asian ones
+func builtinDateLayout(id int) string {
+	switch {
+	case id >= 14 && id <= 17, id >= 27 && id <= 31, id == 34, id == 35, id == 36, id >= 50 && id <= 58:
+		return "2006-01-02"
+	case id == 18, id == 20, id == 32:
+		return "15:04"
+	case id == 19, id == 21, id == 33, id == 45, id == 47:
+		return "15:04:05"
+	case id == 22:
+		return "2006-01-02 15:04"
+	}
+	return ""
+}
+
+// dateLayout returns the ISO layout for a custom number format code that
+// formats dates or times, "" for other formats. Elapsed times like [h]:mm
+// are durations, not times of day, and are kept as numbers.
+func dateLayout(code string) string {
+	var tokens strings.Builder
+	quoted := false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quoted:
+			quoted = c != '"'
+		case c == '"':
+			quoted = true
+		case c == '[':
+			// colors, locales and conditions, or an elapsed time
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return ""
+			}
+			if strings.Trim(strings.ToLower(code[i+1:i+end]), "hms") == "" {
+				return ""
+			}
+			i += end
+		case c == '\\' || c == '_' || c == '*':
+			// the next character is a literal or padding
+			i++
+		case c == ';':
+			// only the section for positive numbers matters
+			i = len(code)
+		default:
+			tokens.WriteByte(c | 0x20)
+		}
+	}
+
+	format := tokens.String()
+	hasDate := strings.ContainsAny(format, "yd")
+	hasTime := strings.ContainsAny(format, "hs")
+	if !hasDate && !hasTime {
+		// a lone m is a month, as in "mmm"
+		hasDate = strings.Contains(format, "m")
+	}
+
+	layout := ""
+	if hasDate {
+		layout = "2006-01-02"
+	}
+	if hasTime {
+		timeLayout := "15:04"
+		if strings.Contains(format, "s") {
+			timeLayout = "15:04:05"
+		}
+		layout = strings.TrimSpace(layout + " " + timeLayout)
+	}
+	return layout
+}
+
+// excelTime converts a serial date number to a time. Serial 1 is 1900-01-01
+// and Excel counts a 1900-02-29 that never existed, so later serials are one
+// day ahead.
+func excelTime(serial float64, date1904 bool) time.Time {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		base = base.AddDate(0, 0, 1)
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	return base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+}
+
+// parseWorksheet returns the cell values of every row with its row number.
+// Missing cells are filled in, so columns line up.
+func parseWorksheet(data []byte, sharedStrings []string, styles cellStyles) ([][]string, []int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var rows [][]string
+	var rowNumbers []int
+	var row []string
+	var cellType, cellRef, cellStyle string
+	var value strings.Builder
+	inValue := false
+	column := 0
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "row":
+				row = nil
+				column = 0
+				number, err := strconv.Atoi(attr(el, "r"))
+				if err != nil {
+					// the r attribute is optional, rows are then consecutive
+					number = len(rowNumbers) + 1
+					if len(rowNumbers) > 0 {
+						number = rowNumbers[len(rowNumbers)-1] + 1
+					}
+				}
+				rowNumbers = append(rowNumbers, number)
+			case "c":
+				cellType, cellRef, cellStyle = attr(el, "t"), attr(el, "r"), attr(el, "s")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+
+		case xml.CharData:
+			if inValue {
+				value.Write(el)
+			}
+
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				if index, ok := columnIndex(cellRef); ok {
+					column = index
+				}
+				for len(row) < column {
+					row = append(row, "")
+				}
+				row = append(row, cellValue(cellType, cellStyle, value.String(), sharedStrings, styles))
+				column++
+			case "row":
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	return rows, rowNumbers, nil
+}
+
+func cellValue(cellType, style, raw string, sharedStrings []string, styles cellStyles) string {
+	switch cellType {
+	case "s":
+		if i, err := strconv.Atoi(raw); err == nil && i >= 0 && i < len(sharedStrings) {
+			return sharedStrings[i]
+		}
+		return ""
+	case "b":
+		if raw == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "", "n":
+		// numbers formatted as dates are written as ISO dates
+		if layout := styles.layout(style); layout != "" {
+			if serial, err := strconv.ParseFloat(raw, 64); err == nil && serial >= 0 {
+				return excelTime(serial, styles.date1904).Format(layout)
+			}
+		}
+		return raw
+	default:
+		return raw
+	}
+}
+
+// columnIndex turns the letters of a cell reference like "AB12" into a
+// zero-based column index
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+// newTable uses the first non-empty row as the header and drops empty rows
+// and trailing empty columns. It returns nil when there is no data.
+func newTable(rows [][]string, rowNumbers []int) *Table {
+	table := &Table{}
+	headerNumber := 0
+	for i, row := range rows {
+		row = trimEmptyCells(row)
+		if len(row) == 0 {
+			continue
+		}
+		if table.Header == nil {
+			table.Header = row
+			headerNumber = rowNumbers[i]
+			continue
+		}
+		table.Rows = append(table.Rows, row)
+		table.RowNumbers = append(table.RowNumbers, rowNumbers[i])
+	}
+
+	if len(table.Rows) == 0 {
+		// a single row is data, not a header
+		if table.Header == nil {
+			return nil
+		}
+		table.Rows = [][]string{table.Header}
+		table.RowNumbers = []int{headerNumber}
+		table.Header = nil
+	}
+	return table
+}
+
+func trimEmptyCells(row []string) []string {
+	end := len(row)
+	for end > 0 && strings.TrimSpace(row[end-1]) == "" {
+		end--
+	}
+	return row[:end]
+}
@@ -0,0 +1,297 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want rune
+	}{
+		{"comma", "nama,nim,nilai\nAni,1,90", ','},
+		{"semicolon", "nama;nim;nilai\nAni;1;90,5", ';'},
+		{"tab", "nama\tnim\tnilai\n", '\t'},
+		{"pipe", "nama|nim|nilai", '|'},
+		// only the header line counts, decimal commas below don't
+		{"decimal commas in data", "nama;nilai\nAni;90,5\nBudi;80,25", ';'},
+		{"single column", "nama\nAni", ','},
+	}
+
+	for _, test := range tests {
+		if got := detectDelimiter(test.text); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCSVExtractor(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *Table
+	}{
+		{
+			name: "header and rows",
+			data: "nama,nilai\nAni,90\nBudi,80\n",
+			want: &Table{
+				Header:     []string{"nama", "nilai"},
+				Rows:       [][]string{{"Ani", "90"}, {"Budi", "80"}},
+				RowNumbers: []int{2, 3},
+			},
+		},
+		{
+			name: "semicolons and blank lines",
+			data: "nama;nilai\n\nAni;90,5\n\n\nBudi;80\n",
+			want: &Table{
+				Header:     []string{"nama", "nilai"},
+				Rows:       [][]string{{"Ani", "90,5"}, {"Budi", "80"}},
+				RowNumbers: []int{3, 6},
+			},
+		},
+		{
+			name: "quoted fields over several lines",
+			data: "nama,catatan\nAni,\"baris satu\nbaris dua\"\nBudi,ok\n",
+			want: &Table{
+				Header:     []string{"nama", "catatan"},
+				Rows:       [][]string{{"Ani", "baris satu\nbaris dua"}, {"Budi", "ok"}},
+				RowNumbers: []int{2, 4},
+			},
+		},
+		{
+			name: "single row",
+			data: "Ani,90\n",
+			want: &Table{Rows: [][]string{{"Ani", "90"}}, RowNumbers: []int{1}},
+		},
+		{
+			name: "empty",
+			data: "\n\n",
+		},
+	}
+
+	for _, test := range tests {
+		segments, err := (&CSVExtractor{}).Extract([]byte(test.data))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if test.want == nil {
+			if len(segments) != 0 {
+				t.Errorf("%s: got %d segments, want none", test.name, len(segments))
+			}
+			continue
+		}
+		if len(segments) != 1 {
+			t.Fatalf("%s: got %d segments, want one table", test.name, len(segments))
+		}
+		if !reflect.DeepEqual(segments[0].Table, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, segments[0].Table, test.want)
+		}
+	}
+}
+
+func TestParseWorksheetFillsMissingCells(t *testing.T) {
+	tests := []struct {
+		name       string
+		sheet      string
+		rows       [][]string
+		rowNumbers []int
+	}{
+		{
+			name: "gaps between cells",
+			sheet: `<row r="1"><c r="A1" t="inlineStr"><is><t>a</t></is></c><c r="C1"><v>3</v></c></row>` +
+				`<row r="4"><c r="B4"><v>2</v></c></row>`,
+			rows:       [][]string{{"a", "", "3"}, {"", "2"}},
+			rowNumbers: []int{1, 4},
+		},
+		{
+			name:       "cells without references follow each other",
+			sheet:      `<row r="2"><c><v>1</v></c><c><v>2</v></c><c r="E2"><v>5</v></c><c><v>6</v></c></row>`,
+			rows:       [][]string{{"1", "2", "", "", "5", "6"}},
+			rowNumbers: []int{2},
+		},
+		{
+			name:       "rows without numbers follow the previous row",
+			sheet:      `<row r="3"><c><v>1</v></c></row><row><c><v>2</v></c></row>`,
+			rows:       [][]string{{"1"}, {"2"}},
+			rowNumbers: []int{3, 4},
+		},
+		{
+			name:       "shared strings and booleans",
+			sheet:      `<row r="1"><c r="A1" t="s"><v>1</v></c><c r="B1" t="b"><v>1</v></c></row>`,
+			rows:       [][]string{{"satu", "TRUE"}},
+			rowNumbers: []int{1},
+		},
+	}
+
+	for _, test := range tests {
+		data := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			test.sheet + `</sheetData></worksheet>`
+		rows, rowNumbers, err := parseWorksheet([]byte(data), []string{"nol", "satu"}, cellStyles{})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(rows, test.rows) {
+			t.Errorf("%s: got rows %q, want %q", test.name, rows, test.rows)
+		}
+		if !reflect.DeepEqual(rowNumbers, test.rowNumbers) {
+			t.Errorf("%s: got row numbers %v, want %v", test.name, rowNumbers, test.rowNumbers)
+		}
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref   string
+		index int
+		ok    bool
+	}{
+		{"A1", 0, true},
+		{"B7", 1, true},
+		{"Z9", 25, true},
+		{"AA10", 26, true},
+		{"AZ1", 51, true},
+		{"XFD1048576", 16383, true},
+		{"12", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		index, ok := columnIndex(test.ref)
+		if index != test.index || ok != test.ok {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d, %v", test.ref, index, ok, test.index, test.ok)
+		}
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows       [][]string
+		rowNumbers []int
+		want       *Table
+	}{
+		{
+			name:       "first non-empty row is the header",
+			rows:       [][]string{{"", ""}, {"nama", "nilai", ""}, {"Ani", "90"}, {}, {"Budi", "80", " "}},
+			rowNumbers: []int{1, 2, 3, 4, 7},
+			want: &Table{
+				Header:     []string{"nama", "nilai"},
+				Rows:       [][]string{{"Ani", "90"}, {"Budi", "80"}},
+				RowNumbers: []int{3, 7},
+			},
+		},
+		{
+			name:       "a single row is data",
+			rows:       [][]string{{}, {"Ani", "90", ""}},
+			rowNumbers: []int{1, 5},
+			want:       &Table{Rows: [][]string{{"Ani", "90"}}, RowNumbers: []int{5}},
+		},
+		{
+			name:       "no data",
+			rows:       [][]string{{"", " "}, {}},
+			rowNumbers: []int{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		if got := newTable(test.rows, test.rowNumbers); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"yyyy-mm-dd", "2006-01-02"},
+		{"d/m/yyyy", "2006-01-02"},
+		{"mmm yyyy", "2006-01-02"},
+		{"[$-421]dd mmmm yyyy;@", "2006-01-02"},
+		{"dd/mm/yyyy hh:mm", "2006-01-02 15:04"},
+		{"yyyy-mm-dd hh:mm:ss", "2006-01-02 15:04:05"},
+		{"h:mm AM/PM", "15:04"},
+		{"[Magenta]hh:mm:ss", "15:04:05"},
+		// elapsed durations and plain numbers stay numbers
+		{"[h]:mm:ss", ""},
+		{"[mm]:ss", ""},
+		{"0.00", ""},
+		{"#,##0;[Red]-#,##0", ""},
+		{`0" days"`, ""},
+		{`\d0`, ""},
+		{"General", ""},
+	}
+
+	for _, test := range tests {
+		if got := dateLayout(test.code); got != test.want {
+			t.Errorf("dateLayout(%q) = %q, want %q", test.code, got, test.want)
+		}
+	}
+}
+
+func TestXLSXExtractorWritesDatesAsISO(t *testing.T) {
+	workbook := func(date1904 string) []byte {
+		return buildZip(t, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+				`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+				`<workbookPr` + date1904 + `/><sheets><sheet name="Jadwal" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+				`</Relationships>`,
+			// style 0 is a plain number, 1 the built-in date, 2 a custom
+			// datetime, 3 the built-in time, 4 an elapsed duration
+			"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+				`<numFmts count="2"><numFmt numFmtId="164" formatCode="dd/mm/yyyy hh:mm"/><numFmt numFmtId="165" formatCode="[h]:mm"/></numFmts>` +
+				`<cellStyleXfs count="1"><xf numFmtId="14"/></cellStyleXfs>` +
+				`<cellXfs count="5"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="20"/><xf numFmtId="165"/></cellXfs>` +
+				`</styleSheet>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+				`<row r="1"><c r="A1" t="inlineStr"><is><t>angka</t></is></c><c r="B1" t="inlineStr"><is><t>tanggal</t></is></c>` +
+				`<c r="C1" t="inlineStr"><is><t>mulai</t></is></c><c r="D1" t="inlineStr"><is><t>jam</t></is></c>` +
+				`<c r="E1" t="inlineStr"><is><t>durasi</t></is></c></row>` +
+				`<row r="2"><c r="A2" s="0"><v>45321.5</v></c><c r="B2" s="1"><v>45321</v></c>` +
+				`<c r="C2" s="2"><v>45321.5</v></c><c r="D2" s="3"><v>0.375</v></c><c r="E2" s="4"><v>1.5</v></c></row>` +
+				`<row r="3"><c r="B3" s="1"><v>1</v></c><c r="C3" s="2"><v>61.25</v></c></row>` +
+				`</sheetData></worksheet>`,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		date1904 string
+		rows     [][]string
+	}{
+		{
+			name: "1900 dates",
+			rows: [][]string{
+				{"45321.5", "2024-01-30", "2024-01-30 12:00", "09:00", "1.5"},
+				{"", "1900-01-01", "1900-03-01 06:00"},
+			},
+		},
+		{
+			name:     "1904 dates",
+			date1904: ` date1904="1"`,
+			rows: [][]string{
+				{"45321.5", "2028-01-31", "2028-01-31 12:00", "09:00", "1.5"},
+				{"", "1904-01-02", "1904-03-02 06:00"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		segments, err := (&XLSXExtractor{}).Extract(workbook(test.date1904))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(segments) != 1 || segments[0].SheetName != "Jadwal" {
+			t.Fatalf("%s: got %+v, want the Jadwal sheet", test.name, segments)
+		}
+		if !reflect.DeepEqual(segments[0].Table.Rows, test.rows) {
+			t.Errorf("%s: got rows %q, want %q", test.name, segments[0].Table.Rows, test.rows)
+		}
+	}
+}
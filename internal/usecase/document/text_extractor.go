@@ -1,41 +1,98 @@
-package document
-
-import (
-	"bytes"
-	"fmt"
-
-	"github.com/ledongthuc/pdf"
-)
-
-type TextExtractor struct{}
-
-func NewTextExtractor() *TextExtractor {
-	return &TextExtractor{}
-}
-
-func (te *TextExtractor) ExtractFromPDF(data []byte) (string, error) {
-	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
-	if err != nil {
-		return "", fmt.Errorf("failed to create PDF reader: %w", err)
-	}
-
-	var fullText string
-	// Get total pages
-	numPages := reader.NumPage()
-
-	for i := 1; i <= numPages; i++ {
-		page := reader.Page(i)
-		if page.V.IsNull() {
-			continue
-		}
-
-		text, err := page.GetPlainText(nil)
-		if err != nil {
-			continue
-		}
-
-		fullText += text + "\n"
-	}
-
-	return fullText, nil
-}
+package document
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+const (
+	MimeTypePDF  = "application/pdf"
+	MimeTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeTypePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MimeTypeText = "text/plain"
+	MimeTypeMD   = "text/markdown"
+	MimeTypeHTML = "text/html"
+	MimeTypeCSV  = "text/csv"
+	MimeTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// Segment is a piece of extracted text together with where it came from.
+// Fields that don't apply to the file type are left zero.
+type Segment struct {
+	Text        string
+	PageNumber  int
+	SlideNumber int
+	// HeadingPath holds the headings the text is under, outermost first
+	HeadingPath []string
+	SheetName   string
+	// Table is set instead of Text for tabular data
+	Table *Table
+	// OCR is set for text recognized from an image, Confidence is then the
+	// engine's confidence between 0 and 1
+	OCR        bool
+	Confidence float64
+}
+
+// Table is tabular data, which is chunked by rows instead of as text
+type Table struct {
+	Header []string
+	Rows   [][]string
+	// RowNumbers are the sheet row numbers of Rows, empty rows are skipped
+	RowNumbers []int
+}
+
+// Extractor turns the bytes of one file type into text segments
+type Extractor interface {
+	Extract(data []byte) ([]Segment, error)
+}
+
+// TextExtractor dispatches to the Extractor registered for a MIME type
+type TextExtractor struct {
+	extractors map[string]Extractor
+}
+
+func NewTextExtractor() *TextExtractor {
+	te := &TextExtractor{extractors: make(map[string]Extractor)}
+	te.Register(MimeTypePDF, &PDFExtractor{})
+	te.Register(MimeTypeDOCX, &DOCXExtractor{})
+	te.Register(MimeTypePPTX, &PPTXExtractor{})
+	te.Register(MimeTypeText, &PlainTextExtractor{})
+	te.Register(MimeTypeMD, &MarkdownExtractor{})
+	te.Register("text/x-markdown", &MarkdownExtractor{})
+	te.Register(MimeTypeHTML, &HTMLExtractor{})
+	te.Register(MimeTypeCSV, &CSVExtractor{})
+	te.Register("text/tab-separated-values", &CSVExtractor{})
+	te.Register(MimeTypeXLSX, &XLSXExtractor{})
+	return te
+}
+
+// Register adds or replaces the extractor for a MIME type
+func (te *TextExtractor) Register(mimeType string, extractor Extractor) {
+	te.extractors[mediaType(mimeType)] = extractor
+}
+
+// Supports reports whether there is an extractor for the MIME type
+func (te *TextExtractor) Supports(mimeType string) bool {
+	_, ok := te.extractors[mediaType(mimeType)]
+	return ok
+}
+
+func (te *TextExtractor) Extract(mimeType string, data []byte) ([]Segment, error) {
+	extractor, ok := te.extractors[mediaType(mimeType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, mimeType)
+	}
+	return extractor.Extract(data)
+}
+
+// mediaType drops parameters such as "; charset=utf-8" from a MIME type
+func mediaType(mimeType string) string {
+	if parsed, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
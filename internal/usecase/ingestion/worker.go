@@ -0,0 +1,267 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"rag-api/internal/domain/entity"
+	"rag-api/internal/domain/repository"
+)
+
+// maxBackoff caps the delay between retries of a job
+const maxBackoff = 30 * time.Minute
+
+type DocumentProcessor interface {
+	ProcessDocument(ctx context.Context, documentID string, mimeType string) error
+}
+
+// Worker runs queued ingestion jobs with a bounded pool of goroutines. Failed
+// jobs are retried with exponential backoff until maxAttempts is reached, after
+// which the document is marked FAILED.
+type Worker struct {
+	jobRepo      repository.JobRepository
+	docRepo      repository.DocumentRepository
+	processor    DocumentProcessor
+	workers      int
+	maxAttempts  int
+	backoff      time.Duration
+	pollInterval time.Duration
+	staleAfter   time.Duration
+
+	wg sync.WaitGroup
+}
+
+func NewWorker(
+	jobRepo repository.JobRepository,
+	docRepo repository.DocumentRepository,
+	processor DocumentProcessor,
+	workers, maxAttempts int,
+	backoff, pollInterval, staleAfter time.Duration,
+) *Worker {
+	return &Worker{
+		jobRepo:      jobRepo,
+		docRepo:      docRepo,
+		processor:    processor,
+		workers:      workers,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+		pollInterval: pollInterval,
+		staleAfter:   staleAfter,
+	}
+}
+
+// Start recovers jobs left RUNNING by a previous process and starts the pool.
+// The workers stop once ctx is cancelled; Wait blocks until they are done.
+func (w *Worker) Start(ctx context.Context) error {
+	if err := w.recoverStale(ctx); err != nil {
+		return fmt.Errorf("failed to recover stale jobs: %w", err)
+	}
+
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go w.loop(ctx)
+	}
+	log.Printf("Started %d ingestion workers", w.workers)
+
+	w.wg.Add(1)
+	go w.recoverLoop(ctx)
+
+	return nil
+}
+
+// Wait blocks until every worker has stopped
+func (w *Worker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		// drain the queue before sleeping again
+		for ctx.Err() == nil && w.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// recoverLoop keeps recovering stale jobs while the workers run. Jobs of a
+// process that restarted before they went stale are only stale later on, and
+// other processes sharing the queue may die at any time.
+func (w *Worker) recoverLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.staleAfter / 2):
+		}
+
+		if err := w.recoverStale(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to recover stale ingestion jobs: %v", err)
+		}
+	}
+}
+
+func (w *Worker) recoverStale(ctx context.Context) error {
+	recovered, err := w.jobRepo.RecoverStale(ctx, w.staleAfter)
+	if err != nil {
+		return err
+	}
+	if recovered > 0 {
+		log.Printf("Recovered %d stale ingestion jobs", recovered)
+	}
+	return nil
+}
+
+// runNext claims and runs one job, reporting whether there was one
+func (w *Worker) runNext(ctx context.Context) bool {
+	job, err := w.jobRepo.Claim(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to claim ingestion job: %v", err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	// a job recovered after crashing the worker every time must not loop forever
+	if job.Attempts > w.maxAttempts {
+		w.fail(job, fmt.Errorf("gave up after %d attempts", job.Attempts-1))
+		return true
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		w.heartbeat(jobCtx, cancel, job)
+	}()
+	err = w.process(jobCtx, job)
+	cancel()
+	<-heartbeat
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// shutting down, hand the job back for the next process
+			if err := w.jobRepo.Retry(context.Background(), job, 0, "interrupted by shutdown"); err != nil {
+				logUpdateError("release", job, err)
+			}
+			return false
+		}
+		w.handleError(job, err)
+		return true
+	}
+
+	if err := w.jobRepo.Complete(context.Background(), job); err != nil {
+		logUpdateError("complete", job, err)
+	}
+	return true
+}
+
+// heartbeat refreshes the lock of the job while it runs, so jobs running
+// longer than staleAfter aren't recovered from under a live worker. Should
+// the job be recovered anyway, e.g. after the database was unreachable for
+// a while, it is cancelled to leave it to its new claim.
+func (w *Worker) heartbeat(ctx context.Context, cancel context.CancelFunc, job *entity.IngestionJob) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.staleAfter / 4):
+		}
+
+		err := w.jobRepo.Heartbeat(ctx, job)
+		if errors.Is(err, repository.ErrJobNotClaimed) {
+			log.Printf("Ingestion job %s was recovered while running, cancelling it", job.ID)
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to refresh ingestion job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// logUpdateError logs a failed update of a job, which is expected once the
+// job was recovered and claimed again
+func logUpdateError(action string, job *entity.IngestionJob, err error) {
+	if errors.Is(err, repository.ErrJobNotClaimed) {
+		log.Printf("Ingestion job %s was claimed again, leaving it to the new claim", job.ID)
+		return
+	}
+	log.Printf("Failed to %s ingestion job %s: %v", action, job.ID, err)
+}
+
+func (w *Worker) process(ctx context.Context, job *entity.IngestionJob) (err error) {
+	// recovery for panic in document processing
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return w.processor.ProcessDocument(ctx, job.DocumentID, job.MimeType)
+}
+
+func (w *Worker) handleError(job *entity.IngestionJob, err error) {
+	log.Printf("Error processing document %s (attempt %d/%d): %v", job.DocumentID, job.Attempts, w.maxAttempts, err)
+
+	if job.Attempts >= w.maxAttempts {
+		w.fail(job, err)
+		return
+	}
+
+	delay := w.backoffFor(job.Attempts)
+	if err := w.jobRepo.Retry(context.Background(), job, delay, err.Error()); err != nil {
+		logUpdateError("reschedule", job, err)
+	}
+}
+
+func (w *Worker) fail(job *entity.IngestionJob, err error) {
+	ctx := context.Background()
+	if err := w.jobRepo.Fail(ctx, job, err.Error()); err != nil {
+		logUpdateError("fail", job, err)
+		// the document belongs to whoever claimed the job since
+		if errors.Is(err, repository.ErrJobNotClaimed) {
+			return
+		}
+	}
+
+	// a failed reprocess leaves the previous chunks in place and searchable
+	doc, err := w.docRepo.FindByID(ctx, job.DocumentID)
+	if err != nil {
+		log.Printf("Failed to find document %s: %v", job.DocumentID, err)
+		return
+	}
+	if doc == nil || doc.Status == entity.StatusCompleted {
+		return
+	}
+	if err := w.docRepo.UpdateStatus(ctx, job.DocumentID, entity.StatusFailed); err != nil {
+		log.Printf("Failed to mark document %s as failed: %v", job.DocumentID, err)
+	}
+}
+
+// backoffFor doubles the base delay for every attempt already made
+func (w *Worker) backoffFor(attempts int) time.Duration {
+	delay := w.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
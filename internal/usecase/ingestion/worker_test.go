@@ -0,0 +1,119 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rag-api/internal/adapter/repository/memory"
+	"rag-api/internal/domain/entity"
+)
+
+type processorFunc func(ctx context.Context, documentID string, mimeType string) error
+
+func (f processorFunc) ProcessDocument(ctx context.Context, documentID string, mimeType string) error {
+	return f(ctx, documentID, mimeType)
+}
+
+// enqueueDocument queues a job for a new PROCESSING document
+func enqueueDocument(t *testing.T, db *memory.DB) *entity.Document {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &entity.User{Email: "budi@kampus.ac.id", Password: "hashed", Name: "Budi", Major: "Informatika", Role: entity.RoleStudent}
+	if err := memory.NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	doc := &entity.Document{UserID: user.ID, Filename: "materi.txt", MimeType: "text/plain", Status: entity.StatusProcessing, Visibility: entity.VisibilityPrivate}
+	if err := memory.NewDocumentRepository(db).Create(ctx, doc); err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+	if err := memory.NewJobRepository(db).Enqueue(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return doc
+}
+
+// TestWorkerRecoversJobsOfRestartedProcess covers a process that restarted
+// before its RUNNING jobs went stale: Start can't recover them, the workers
+// must pick them up once they do
+func TestWorkerRecoversJobsOfRestartedProcess(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	jobRepo := memory.NewJobRepository(db)
+	docRepo := memory.NewDocumentRepository(db)
+	doc := enqueueDocument(t, db)
+
+	// the previous process claimed the job and died right away
+	if job, err := jobRepo.Claim(ctx); err != nil || job == nil {
+		t.Fatalf("Claim = %v, %v", job, err)
+	}
+
+	processed := make(chan string, 1)
+	worker := NewWorker(jobRepo, docRepo, processorFunc(func(ctx context.Context, documentID string, mimeType string) error {
+		processed <- documentID
+		return nil
+	}), 1, 3, time.Second, 10*time.Millisecond, 100*time.Millisecond)
+
+	workerCtx, stop := context.WithCancel(ctx)
+	defer func() {
+		stop()
+		worker.Wait()
+	}()
+	if err := worker.Start(workerCtx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	select {
+	case documentID := <-processed:
+		if documentID != doc.ID {
+			t.Errorf("processed document %s, want %s", documentID, doc.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the job of the restarted process was never recovered")
+	}
+}
+
+// TestWorkerKeepsLongJobsClaimed covers a job running longer than
+// staleAfter: its heartbeat must keep the other worker from running it again
+func TestWorkerKeepsLongJobsClaimed(t *testing.T) {
+	ctx := context.Background()
+	db := memory.NewDB()
+	jobRepo := memory.NewJobRepository(db)
+	doc := enqueueDocument(t, db)
+
+	runs := make(chan string, 2)
+	worker := NewWorker(jobRepo, memory.NewDocumentRepository(db), processorFunc(func(ctx context.Context, documentID string, mimeType string) error {
+		runs <- documentID
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(400 * time.Millisecond):
+			return nil
+		}
+	}), 2, 3, time.Second, 10*time.Millisecond, 100*time.Millisecond)
+
+	workerCtx, stop := context.WithCancel(ctx)
+	defer func() {
+		stop()
+		worker.Wait()
+	}()
+	if err := worker.Start(workerCtx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the job never ran")
+	}
+
+	// long enough for the job to finish and to go stale several times over
+	time.Sleep(600 * time.Millisecond)
+	if len(runs) != 0 {
+		t.Errorf("the job ran %d more times, want once", len(runs))
+	}
+	if idle, err := jobRepo.EnqueueIfIdle(ctx, &entity.IngestionJob{DocumentID: doc.ID, MimeType: doc.MimeType}); err != nil || !idle {
+		t.Errorf("EnqueueIfIdle = %v, %v, want the job completed", idle, err)
+	}
+}
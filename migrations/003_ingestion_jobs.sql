@@ -0,0 +1,26 @@
+-- Create JobStatus enum
+CREATE TYPE "JobStatus" AS ENUM ('PENDING', 'RUNNING', 'COMPLETED', 'FAILED');
+
+-- Create ingestion_jobs table
+CREATE TABLE "ingestion_jobs" (
+    "id" TEXT NOT NULL,
+    "documentId" TEXT NOT NULL,
+    "status" "JobStatus" NOT NULL DEFAULT 'PENDING',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "lastError" TEXT,
+    "fileData" BYTEA,
+    "mimeType" TEXT NOT NULL,
+    "runAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "lockedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "ingestion_jobs_pkey" PRIMARY KEY ("id")
+);
+
+-- Create indexes
+CREATE INDEX "ingestion_jobs_status_runAt_idx" ON "ingestion_jobs"("status", "runAt");
+CREATE INDEX "ingestion_jobs_documentId_idx" ON "ingestion_jobs"("documentId");
+
+-- Add foreign key constraints
+ALTER TABLE "ingestion_jobs" ADD CONSTRAINT "ingestion_jobs_documentId_fkey" FOREIGN KEY ("documentId") REFERENCES "documents"("id") ON DELETE CASCADE ON UPDATE CASCADE;
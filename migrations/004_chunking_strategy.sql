@@ -0,0 +1,2 @@
+-- Chunking strategy chosen at upload, empty uses the configured default
+ALTER TABLE "documents" ADD COLUMN "chunkingStrategy" TEXT NOT NULL DEFAULT '';
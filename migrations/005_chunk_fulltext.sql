@@ -0,0 +1,7 @@
+-- Full-text index of chunk contents for hybrid search. The simple
+-- configuration doesn't stem, so course codes, formula names and acronyms
+-- are matched exactly in any language.
+ALTER TABLE "document_chunks" ADD COLUMN "contentTsv" tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', "content")) STORED;
+
+CREATE INDEX "document_chunks_contentTsv_idx" ON "document_chunks" USING GIN("contentTsv");
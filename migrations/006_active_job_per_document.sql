@@ -0,0 +1,15 @@
+-- At most one PENDING or RUNNING job per document, so concurrent reprocess
+-- requests can't queue the same document twice. Duplicates queued before the
+-- index existed are failed first, keeping the oldest job.
+UPDATE "ingestion_jobs" SET "status" = 'FAILED', "lastError" = 'duplicate active job', "lockedAt" = NULL, "updatedAt" = CURRENT_TIMESTAMP
+WHERE "id" IN (
+    SELECT "id" FROM (
+        SELECT "id", ROW_NUMBER() OVER (PARTITION BY "documentId" ORDER BY "createdAt") AS "position"
+        FROM "ingestion_jobs"
+        WHERE "status" IN ('PENDING', 'RUNNING')
+    ) "active"
+    WHERE "position" > 1
+);
+
+CREATE UNIQUE INDEX "ingestion_jobs_documentId_active_key" ON "ingestion_jobs"("documentId")
+    WHERE "status" IN ('PENDING', 'RUNNING');
@@ -0,0 +1,21 @@
+-- Order messages by a sequence instead of "createdAt", which a question and
+-- its answer written in the same millisecond share. Existing messages are
+-- numbered by time, questions before answers.
+ALTER TABLE "messages" ADD COLUMN "sequence" BIGINT;
+
+UPDATE "messages" SET "sequence" = "numbered"."sequence"
+FROM (
+    SELECT "id", ROW_NUMBER() OVER (ORDER BY "createdAt", "role") AS "sequence"
+    FROM "messages"
+) "numbered"
+WHERE "messages"."id" = "numbered"."id";
+
+CREATE SEQUENCE "messages_sequence_seq" OWNED BY "messages"."sequence";
+SELECT setval('"messages_sequence_seq"', COALESCE(MAX("sequence"), 0) + 1, false) FROM "messages";
+
+ALTER TABLE "messages"
+    ALTER COLUMN "sequence" SET DEFAULT nextval('"messages_sequence_seq"'),
+    ALTER COLUMN "sequence" SET NOT NULL;
+
+DROP INDEX "messages_conversationId_createdAt_idx";
+CREATE INDEX "messages_conversationId_sequence_idx" ON "messages"("conversationId", "sequence");
@@ -0,0 +1,3 @@
+-- Ingestion jobs read the original file from blob storage, where every upload
+-- is kept since the blob store was added, instead of a copy in the job
+ALTER TABLE "ingestion_jobs" DROP COLUMN "fileData";
@@ -1,81 +1,209 @@
-package config
-
-import (
-	"os"
-	"strconv"
-	"time"
-
-	"github.com/joho/godotenv"
-)
-
-type Config struct {
-	DatabaseURL   string
-	JWTSecret     string
-	JWTExpiration time.Duration
-	Port          int
-
-	// open ai
-	OpenAIKey            string
-	OpenAIEmbeddingModel string
-	OpenAIChatModel      string
-
-	// rag config
-	ChunkSize           int
-	ChunkOverlap        int
-	TopKResults         int
-	SimilarityThreshold float64
-}
-
-func Load() *Config {
-	godotenv.Load()
-	jwtExp, _ := time.ParseDuration(getEnv("JWT_EXPIRATION", "168h"))
-
-	port, err := strconv.Atoi(getEnv("PORT", "8080"))
-	if err != nil {
-		port = 8080
-	}
-
-	return &Config{
-		DatabaseURL:   getEnv("DATABASE_URL", ""),
-		JWTSecret:     getEnv("JWT_SECRET", ""),
-		JWTExpiration: jwtExp,
-		Port:          port,
-
-		// OpenAI
-		OpenAIKey:            getEnv("OPENAI_API_KEY", ""),
-		OpenAIEmbeddingModel: getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
-		OpenAIChatModel:      getEnv("OPENAI_CHAT_MODEL", "gpt-4o-mini"),
-
-		// RAG Config
-		ChunkSize:           getEnvInt("CHUNK_SIZE", 1000),
-		ChunkOverlap:        getEnvInt("CHUNK_OVERLAP", 200),
-		TopKResults:         getEnvInt("TOP_K_RESULTS", 6),
-		SimilarityThreshold: getEnvFloat("SIMILARITY_THRESHOLD", 0.5),
-	}
-
-}
-
-func getEnv(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-	return defaultVal
-}
-
-func getEnvInt(key string, defaultVal int) int {
-	if val := os.Getenv(key); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			return i
-		}
-	}
-	return defaultVal
-}
-
-func getEnvFloat(key string, defaultVal float64) float64 {
-	if val := os.Getenv(key); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			return f
-		}
-	}
-	return defaultVal
-}
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	DatabaseURL   string
+	JWTSecret     string
+	JWTExpiration time.Duration
+	Port          int
+
+	// llm provider: openai, ollama, openai-compatible or fake
+	LLMProvider string
+	LLMBaseURL  string
+
+	// open ai, also used by the other providers.
+	// empty models fall back to the provider's defaults
+	OpenAIKey            string
+	OpenAIEmbeddingModel string
+	OpenAIChatModel      string
+
+	// rag config, chunk sizes are in tokens of the embedding model
+	ChunkSize           int
+	ChunkOverlap        int
+	EmbeddingMaxTokens  int
+	TopKResults         int
+	SimilarityThreshold float64
+
+	// chunking strategy of uploads that don't choose one: fixed, recursive
+	// or semantic
+	ChunkingStrategy             string
+	SemanticMinTokens            int
+	SemanticBreakpointPercentile float64
+
+	// search mode of queries that don't choose one: vector or hybrid, with
+	// the reciprocal rank fusion weights of hybrid searches
+	SearchMode          string
+	HybridVectorWeight  float64
+	HybridKeywordWeight float64
+	HybridRRFK          int
+	// relevance against diversity of the retrieved chunks, 1 turns MMR off
+	MMRLambda float64
+	// chunks before and after every hit added to its context
+	NeighbourChunks int
+	// query expansion of queries that don't choose one: none, multi-query
+	// or hyde
+	QueryExpansion  string
+	MultiQueryCount int
+
+	// reranker of the retrieved chunks: none, llm, cross-encoder or fake.
+	// The cross-encoder runs on a text-embeddings-inference server.
+	Reranker    string
+	RerankerURL string
+
+	// chat config
+	ChatHistoryLimit int
+
+	// blob storage for the original files: local or s3
+	BlobStorage    string
+	BlobLocalDir   string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+
+	// ocr for scanned PDFs and images: none, tesseract or fake
+	OCREngine     string
+	TesseractPath string
+	PdftoppmPath  string
+	OCRLanguages  string
+	OCRDPI        int
+
+	// ingestion config
+	IngestionWorkers      int
+	IngestionMaxAttempts  int
+	IngestionRetryBackoff time.Duration
+	IngestionPollInterval time.Duration
+	IngestionStaleAfter   time.Duration
+}
+
+func Load() *Config {
+	godotenv.Load()
+	jwtExp, _ := time.ParseDuration(getEnv("JWT_EXPIRATION", "168h"))
+
+	port, err := strconv.Atoi(getEnv("PORT", "8080"))
+	if err != nil {
+		port = 8080
+	}
+
+	return &Config{
+		DatabaseURL:   getEnv("DATABASE_URL", ""),
+		JWTSecret:     getEnv("JWT_SECRET", ""),
+		JWTExpiration: jwtExp,
+		Port:          port,
+
+		// LLM Provider
+		LLMProvider: getEnv("LLM_PROVIDER", "openai"),
+		LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
+
+		// OpenAI
+		OpenAIKey:            getEnv("OPENAI_API_KEY", ""),
+		OpenAIEmbeddingModel: getEnv("OPENAI_EMBEDDING_MODEL", ""),
+		OpenAIChatModel:      getEnv("OPENAI_CHAT_MODEL", ""),
+
+		// RAG Config
+		ChunkSize:           getEnvInt("CHUNK_SIZE_TOKENS", 256),
+		ChunkOverlap:        getEnvInt("CHUNK_OVERLAP_TOKENS", 50),
+		EmbeddingMaxTokens:  getEnvInt("EMBEDDING_MAX_TOKENS", 8191),
+		TopKResults:         getEnvInt("TOP_K_RESULTS", 6),
+		SimilarityThreshold: getEnvFloat("SIMILARITY_THRESHOLD", 0.5),
+
+		// Chunking Config
+		ChunkingStrategy:             getEnv("CHUNKING_STRATEGY", "fixed"),
+		SemanticMinTokens:            getEnvInt("SEMANTIC_MIN_TOKENS", 64),
+		SemanticBreakpointPercentile: getEnvFloat("SEMANTIC_BREAKPOINT_PERCENTILE", 90),
+
+		// Search Config
+		SearchMode:          getEnv("SEARCH_MODE", "vector"),
+		HybridVectorWeight:  getEnvFloat("HYBRID_VECTOR_WEIGHT", 1),
+		HybridKeywordWeight: getEnvFloat("HYBRID_KEYWORD_WEIGHT", 1),
+		HybridRRFK:          getEnvInt("HYBRID_RRF_K", 60),
+		MMRLambda:           getEnvFloat("MMR_LAMBDA", 1),
+		NeighbourChunks:     getEnvInt("NEIGHBOUR_CHUNKS", 0),
+		QueryExpansion:      getEnv("QUERY_EXPANSION", "none"),
+		MultiQueryCount:     getEnvInt("MULTI_QUERY_COUNT", 3),
+
+		// Reranker Config
+		Reranker:    getEnv("RERANKER", "none"),
+		RerankerURL: getEnv("RERANKER_URL", ""),
+
+		// Chat Config
+		ChatHistoryLimit: getEnvInt("CHAT_HISTORY_LIMIT", 10),
+
+		// Blob Storage
+		BlobStorage:    getEnv("BLOB_STORAGE", "local"),
+		BlobLocalDir:   getEnv("BLOB_LOCAL_DIR", "./uploads"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       getEnv("S3_BUCKET", ""),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle: getEnvBool("S3_USE_PATH_STYLE", false),
+
+		// OCR Config
+		OCREngine:     getEnv("OCR_ENGINE", "none"),
+		TesseractPath: getEnv("TESSERACT_PATH", "tesseract"),
+		PdftoppmPath:  getEnv("PDFTOPPM_PATH", "pdftoppm"),
+		OCRLanguages:  getEnv("OCR_LANGUAGES", "ind+eng"),
+		OCRDPI:        getEnvInt("OCR_DPI", 300),
+
+		// Ingestion Config
+		IngestionWorkers:      getEnvInt("INGESTION_WORKERS", 2),
+		IngestionMaxAttempts:  getEnvInt("INGESTION_MAX_ATTEMPTS", 3),
+		IngestionRetryBackoff: getEnvDuration("INGESTION_RETRY_BACKOFF", 30*time.Second),
+		IngestionPollInterval: getEnvDuration("INGESTION_POLL_INTERVAL", 2*time.Second),
+		IngestionStaleAfter:   getEnvDuration("INGESTION_STALE_AFTER", 15*time.Minute),
+	}
+
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+func getEnvInt(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if i, err := strconv.Atoi(val); err == nil {
+			return i
+		}
+	}
+	return defaultVal
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+	}
+	return defaultVal
+}
@@ -0,0 +1,51 @@
+package tokenizer
+
+import (
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// defaultEncoding is used for models tiktoken doesn't know, like local ones.
+// It is close enough to size chunks, not to count tokens exactly.
+const defaultEncoding = "cl100k_base"
+
+func init() {
+	// the encodings are embedded in the binary, nothing is downloaded
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+// BPE counts tokens with the byte pair encoding of an OpenAI model
+type BPE struct {
+	encoding *tiktoken.Tiktoken
+}
+
+// ForModel returns the tokenizer of an embedding model
+func ForModel(model string) (*BPE, error) {
+	encoding, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		encoding, err = tiktoken.GetEncoding(defaultEncoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &BPE{encoding: encoding}, nil
+}
+
+// Count returns the number of tokens of text. Special tokens like
+// <|endoftext|> are counted as plain text, as the embedding API does.
+func (b *BPE) Count(text string) int {
+	return len(b.encoding.EncodeOrdinary(text))
+}
+
+// Offsets returns the byte offset at which every token of text starts. A
+// token can start inside a multi-byte rune.
+func (b *BPE) Offsets(text string) []int {
+	tokens := b.encoding.EncodeOrdinary(text)
+	offsets := make([]int, len(tokens))
+	pos := 0
+	for i, token := range tokens {
+		offsets[i] = pos
+		pos += len(b.encoding.Decode([]int{token}))
+	}
+	return offsets
+}